@@ -19,71 +19,149 @@ func init() {
 func newActivityCmd() *cobra.Command {
 	var (
 		distinctIDs string
+		idsFile     string
 		from        string
 		to          string
+		event       string
+		where       string
+		limit       int
+		columns     string
+		timezone    string
+		rawTime     bool
 	)
 
 	cmd := &cobra.Command{
 		Use:   "activity",
 		Short: "Query user activity stream",
 		Long: `Query the activity stream for specific users. Shows recent events performed
-by one or more users identified by their distinct IDs.`,
+by one or more users identified by their distinct IDs.
+
+The table lists events newest first, across all requested users, with a
+DISTINCT_ID column showing which user performed each event. The stream endpoint
+returns every event in the range in a single response and has no paging, so use
+--limit to cap the number of rows shown. --json always returns the full
+response, except that --event filtering also applies to it.
+
+For many users, --distinct-ids-file reads IDs from a file (or stdin with "-"),
+one per line or as a JSON array. Duplicate IDs are dropped, and the IDs are
+queried in batches of 100 (up to --concurrency at once) whose events are merged
+into one stream.
+
+Filtering: the stream endpoint itself filters by distinct IDs and date range,
+and --where is passed through to it as a filter expression. --event is applied
+by mp to the returned events, so it does not reduce the amount of data fetched.`,
 		Example: `  # Activity for a single user
   mp activity --distinct-ids "user123" --from 2024-01-01 --to 2024-01-31
 
   # Activity for multiple users
   mp activity --distinct-ids "user1,user2,user3" --from 2024-01-01 --to 2024-01-31
 
+  # Activity for users listed in a file
+  mp activity --distinct-ids-file users.txt --from 2024-01-01 --to 2024-01-31
+
+  # Only purchases
+  mp activity --distinct-ids "user123" --from 2024-01-01 --to 2024-01-31 --event "Purchase"
+
+  # Only the 20 most recent events
+  mp activity --distinct-ids "user123" --from 2024-01-01 --to 2024-01-31 --limit 20
+
+  # Choose the property columns instead of auto-discovering them
+  mp activity --distinct-ids "user123" --from 2024-01-01 --to 2024-01-31 \
+    --columns '$browser,$city,plan'
+
+  # Show times in New York time
+  mp activity --distinct-ids "user123" --from 2024-01-01 --to 2024-01-31 \
+    --timezone America/New_York
+
   # JSON output
   mp activity --distinct-ids "user123" --from 2024-01-01 --to 2024-01-31 --json`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runActivity(cmd, distinctIDs, from, to)
+			if timezone != "" && rawTime {
+				return fmt.Errorf("`--timezone` and `--raw-time` cannot be used together")
+			}
+			loc := time.UTC
+			if timezone != "" {
+				var err error
+				if loc, err = time.LoadLocation(timezone); err != nil {
+					return fmt.Errorf("invalid `--timezone` %q: %w", timezone, err)
+				}
+			}
+			ids, err := resolveDistinctIDs(distinctIDs, idsFile)
+			if err != nil {
+				return err
+			}
+			opts := activityTableOptions{limit: limit, columns: splitCSV(columns), location: loc, rawTime: rawTime}
+			return runActivity(cmd, ids, from, to, event, where, opts)
 		},
 	}
 
-	cmd.Flags().StringVar(&distinctIDs, "distinct-ids", "", "Comma-separated distinct IDs (required)")
-	cmd.Flags().StringVar(&from, "from", "", "Start date yyyy-mm-dd (required)")
-	cmd.Flags().StringVar(&to, "to", "", "End date yyyy-mm-dd (required)")
-
-	_ = cmd.MarkFlagRequired("distinct-ids")
-	_ = cmd.MarkFlagRequired("from")
-	_ = cmd.MarkFlagRequired("to")
+	cmd.Flags().StringVar(&distinctIDs, "distinct-ids", "", "Comma-separated distinct IDs (this or --distinct-ids-file is required)")
+	cmd.Flags().StringVar(&idsFile, "distinct-ids-file", "", "File of distinct IDs, one per line or a JSON array; - reads stdin")
+	addDateRangeFlags(cmd, &from, &to, true)
+	cmd.Flags().StringVar(&event, "event", "", "Comma-separated event names to include (filtered client-side)")
+	cmd.Flags().StringVar(&where, "where", "", "Filter expression passed to the stream endpoint")
+	cmd.Flags().IntVar(&limit, "limit", 0, "Maximum number of most recent events to show (0 = all)")
+	cmd.Flags().StringVar(&columns, "columns", "", "Comma-separated property columns to show after TIME, DISTINCT_ID and EVENT (default: auto-discover)")
+	cmd.Flags().StringVar(&timezone, "timezone", "", "IANA time zone for the TIME column, e.g. America/New_York (default: UTC)")
+	cmd.Flags().BoolVar(&rawTime, "raw-time", false, "Show TIME as the raw epoch seconds")
 
 	return cmd
 }
 
-func runActivity(cmd *cobra.Command, distinctIDs, from, to string) error {
-	c, err := newClient()
-	if err != nil {
-		return err
-	}
+// streamDistinctIDsBatch is how many distinct IDs mp sends per stream query.
+// Longer lists are split into several queries whose events are merged.
+const streamDistinctIDsBatch = 100
 
-	ids := splitCSV(distinctIDs)
+func runActivity(cmd *cobra.Command, ids []string, from, to, event, where string, opts activityTableOptions) error {
+	if opts.limit < 0 {
+		return fmt.Errorf("`--limit` must not be negative")
+	}
 	if len(ids) == 0 {
-		return fmt.Errorf("`--distinct-ids` must specify at least one ID")
+		return fmt.Errorf("`--distinct-ids` or `--distinct-ids-file` must specify at least one ID")
 	}
 
-	params := url.Values{}
-	if err := addProjectID(params); err != nil {
+	c, err := newClient()
+	if err != nil {
 		return err
 	}
-	params.Set("distinct_ids", toJSONArray(ids))
-	params.Set("from_date", from)
-	params.Set("to_date", to)
 
-	resp, err := c.Get(client.APIFamilyQuery, "/stream/query", params)
-	if err != nil {
-		return fmt.Errorf("querying activity stream: %w", err)
-	}
+	batches := chunkStrings(ids, streamDistinctIDsBatch)
+	results := make([]map[string]any, len(batches))
+	err = forEachConcurrent(len(batches), func(i int) error {
+		params := url.Values{}
+		if err := addProjectID(params); err != nil {
+			return err
+		}
+		params.Set("distinct_ids", toJSONArray(batches[i]))
+		params.Set("from_date", from)
+		params.Set("to_date", to)
+		if where != "" {
+			params.Set("where", where)
+		}
+
+		resp, err := c.Get(client.APIFamilyQuery, "/stream/query", params)
+		if err != nil {
+			return fmt.Errorf("querying activity stream: %w", err)
+		}
+
+		body, err := readResponseBody(resp.Body, resp.StatusCode)
+		if err != nil {
+			return err
+		}
 
-	body, err := readResponseBody(resp.Body, resp.StatusCode)
+		if err := json.Unmarshal(body, &results[i]); err != nil {
+			return fmt.Errorf("parsing activity response: %w", err)
+		}
+		return checkAPIError(results[i])
+	})
 	if err != nil {
 		return err
 	}
 
-	var result map[string]any
-	if err := json.Unmarshal(body, &result); err != nil {
-		return fmt.Errorf("parsing activity response: %w", err)
+	result := mergeActivityResults(results)
+
+	if events := splitCSV(event); len(events) > 0 {
+		filterActivityEvents(result, events)
 	}
 
 	handled, err := handleJSONOutput(cmd, result)
@@ -94,12 +172,77 @@ func runActivity(cmd *cobra.Command, distinctIDs, from, to string) error {
 		return nil
 	}
 
-	return renderActivityTable(result)
+	return renderActivityTable(result, opts)
 }
 
-// renderActivityTable renders the activity stream as a table.
+// mergeActivityResults combines the responses of several stream queries by
+// appending their events to the first response. The table sorts events by
+// time, so their order here does not matter.
+func mergeActivityResults(results []map[string]any) map[string]any {
+	merged := results[0]
+	if len(results) == 1 {
+		return merged
+	}
+
+	var events []any
+	for _, result := range results {
+		r, _ := result["results"].(map[string]any)
+		batch, _ := r["events"].([]any)
+		events = append(events, batch...)
+	}
+	r, ok := merged["results"].(map[string]any)
+	if !ok {
+		r = make(map[string]any)
+		merged["results"] = r
+	}
+	r["events"] = events
+	return merged
+}
+
+// activityTableOptions controls how renderActivityTable presents events.
+type activityTableOptions struct {
+	// limit caps the number of events shown; 0 shows all.
+	limit int
+	// columns lists the property columns to show; when empty they are
+	// discovered from the events.
+	columns []string
+	// location is the time zone used to format event times.
+	location *time.Location
+	// rawTime shows event times as epoch seconds instead of formatting them.
+	rawTime bool
+}
+
+// filterActivityEvents removes events whose name is not in names from the
+// stream response in place.
+func filterActivityEvents(result map[string]any, names []string) {
+	results, ok := result["results"].(map[string]any)
+	if !ok {
+		return
+	}
+	eventsRaw, ok := results["events"].([]any)
+	if !ok {
+		return
+	}
+
+	keep := make(map[string]bool, len(names))
+	for _, n := range names {
+		keep[n] = true
+	}
+
+	filtered := make([]any, 0, len(eventsRaw))
+	for _, evRaw := range eventsRaw {
+		ev, _ := evRaw.(map[string]any)
+		if name, _ := ev["event"].(string); keep[name] {
+			filtered = append(filtered, evRaw)
+		}
+	}
+	results["events"] = filtered
+}
+
+// renderActivityTable renders the activity stream as a table, newest event
+// first.
 // Response shape: {"results": {"events": [{"event": "Page View", "properties": {"time": 1704067200, ...}}]}}
-func renderActivityTable(result map[string]any) error {
+func renderActivityTable(result map[string]any, opts activityTableOptions) error {
 	s := getIO()
 
 	results, ok := result["results"].(map[string]any)
@@ -109,15 +252,24 @@ func renderActivityTable(result map[string]any) error {
 
 	eventsRaw, ok := results["events"].([]any)
 	if !ok || len(eventsRaw) == 0 {
-		s.Printf("No activity found.\n")
-		return nil
+		return emptyResult(s, "No activity found.")
+	}
+
+	total := len(eventsRaw)
+	eventsRaw = sortEventsByTimeDesc(eventsRaw)
+	if opts.limit > 0 && len(eventsRaw) > opts.limit {
+		eventsRaw = eventsRaw[:opts.limit]
 	}
 
-	// Discover key properties from the first few events for column display.
-	keyProps := discoverKeyProperties(eventsRaw)
+	// Use the requested columns, or discover key properties from the first
+	// few events.
+	keyProps := opts.columns
+	if len(keyProps) == 0 {
+		keyProps = discoverKeyProperties(eventsRaw)
+	}
 
-	headers := make([]string, 0, 2+len(keyProps))
-	headers = append(headers, "TIME", "EVENT")
+	headers := make([]string, 0, 3+len(keyProps))
+	headers = append(headers, "TIME", "DISTINCT_ID", "EVENT")
 	headers = append(headers, keyProps...)
 
 	rows := make([][]string, 0, len(eventsRaw))
@@ -132,14 +284,16 @@ func renderActivityTable(result map[string]any) error {
 
 		// Format time.
 		timeStr := ""
-		if t, ok := props["time"].(float64); ok {
-			timeStr = time.Unix(int64(t), 0).UTC().Format("2006-01-02 15:04:05")
-		} else if ts, ok := props["time"].(string); ok {
-			timeStr = ts
+		if opts.rawTime {
+			if v := props["time"]; v != nil {
+				timeStr = csvCell(v)
+			}
+		} else {
+			timeStr = output.FormatEpoch(props["time"], opts.location)
 		}
 
-		row := make([]string, 0, 2+len(keyProps))
-		row = append(row, timeStr, eventName)
+		row := make([]string, 0, 3+len(keyProps))
+		row = append(row, timeStr, eventDistinctID(props), eventName)
 		for _, p := range keyProps {
 			val := ""
 			if v, ok := props[p]; ok && v != nil {
@@ -151,10 +305,48 @@ func renderActivityTable(result map[string]any) error {
 	}
 
 	output.PrintTable(s.Out, headers, rows, s.IsTerminal())
-	s.Printf("\n%s %d events\n", s.Muted("Showing"), len(rows))
+	if len(rows) < total {
+		s.Footer("Showing %d of %s", len(rows), pluralize(total, "event"))
+	} else {
+		s.Footer("Showing %s", pluralize(len(rows), "event"))
+	}
 	return nil
 }
 
+// eventDistinctID returns the user an event belongs to, preferring the
+// distinct_id property and falling back to $distinct_id.
+func eventDistinctID(props map[string]any) string {
+	for _, key := range []string{"distinct_id", "$distinct_id"} {
+		if v, ok := props[key]; ok && v != nil {
+			return fmt.Sprintf("%v", v)
+		}
+	}
+	return ""
+}
+
+// sortEventsByTimeDesc returns a copy of events ordered by properties.time,
+// newest first. The API does not guarantee an order. Events without a numeric
+// time sort last.
+func sortEventsByTimeDesc(events []any) []any {
+	sorted := make([]any, len(events))
+	copy(sorted, events)
+
+	eventTime := func(ev any) (float64, bool) {
+		m, _ := ev.(map[string]any)
+		props, _ := m["properties"].(map[string]any)
+		return output.NumericValue(props["time"])
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ti, iok := eventTime(sorted[i])
+		tj, jok := eventTime(sorted[j])
+		if iok != jok {
+			return iok
+		}
+		return ti > tj
+	})
+	return sorted
+}
+
 // discoverKeyProperties examines the first few events and returns the most
 // common non-internal property names (excluding time, distinct_id, etc.).
 func discoverKeyProperties(events []any) []string {
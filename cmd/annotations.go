@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"sort"
+	"time"
 
 	"github.com/aviadshiber/mp/internal/client"
 	"github.com/aviadshiber/mp/internal/output"
@@ -21,41 +23,78 @@ func newAnnotationsCmd() *cobra.Command {
 		Long:  "List and inspect annotations (notes) attached to dates in your Mixpanel project.",
 	}
 
+	setRequestTimeout(annotationsCmd, metadataRequestTimeout)
+
 	annotationsCmd.AddCommand(newAnnotationsListCmd())
 	annotationsCmd.AddCommand(newAnnotationsGetCmd())
 	return annotationsCmd
 }
 
+// annotationsDefaultDays is how many days back `annotations list` looks when
+// no date range is given.
+const annotationsDefaultDays = 90
+
 func newAnnotationsListCmd() *cobra.Command {
 	var (
 		from string
 		to   string
+		all  bool
+		opts annotationsListOptions
 	)
 
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List annotations",
-		Long:  "List annotations in the project, optionally filtered by date range.",
-		Example: `  # List all annotations
+		Long: fmt.Sprintf(`List annotations in the project, optionally filtered by date range.
+
+Without --from or --to, only the last %d days are listed; use --all to list
+annotations of every date. The table is sorted by date, oldest first, unless
+--sort or --desc is given. --json returns the annotations in the order the
+API returns them.`, annotationsDefaultDays),
+		Example: `  # List the last 90 days of annotations
   mp annotations list
 
   # List annotations for a date range
   mp annotations list --from 2024-01-01 --to 2024-01-31
 
+  # Every annotation, newest first
+  mp annotations list --all --desc
+
   # JSON output
   mp annotations list --json`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runAnnotationsList(cmd, from, to)
+			switch opts.sortBy {
+			case "date", "id":
+			default:
+				return fmt.Errorf("invalid `--sort` %q; must be one of: date, id", opts.sortBy)
+			}
+			if all && (from != "" || to != "") {
+				return fmt.Errorf("`--all` cannot be combined with `--from` or `--to`")
+			}
+			if !all && from == "" && to == "" {
+				now := time.Now()
+				from = now.AddDate(0, 0, -(annotationsDefaultDays - 1)).Format(dateLayout)
+				to = now.Format(dateLayout)
+			}
+			return runAnnotationsList(cmd, from, to, opts)
 		},
 	}
 
-	cmd.Flags().StringVar(&from, "from", "", "Start date yyyy-mm-dd (optional)")
-	cmd.Flags().StringVar(&to, "to", "", "End date yyyy-mm-dd (optional)")
+	addDateRangeFlags(cmd, &from, &to, false)
+	cmd.Flags().BoolVar(&all, "all", false, fmt.Sprintf("List annotations of all dates instead of the last %d days", annotationsDefaultDays))
+	cmd.Flags().StringVar(&opts.sortBy, "sort", "date", "Sort by: date, id")
+	cmd.Flags().BoolVar(&opts.desc, "desc", false, "Sort in descending order")
 
 	return cmd
 }
 
-func runAnnotationsList(cmd *cobra.Command, from, to string) error {
+// annotationsListOptions controls the order of `annotations list` tables.
+type annotationsListOptions struct {
+	sortBy string // date or id
+	desc   bool
+}
+
+func runAnnotationsList(cmd *cobra.Command, from, to string, opts annotationsListOptions) error {
 	c, err := newClient()
 	if err != nil {
 		return err
@@ -98,17 +137,47 @@ func runAnnotationsList(cmd *cobra.Command, from, to string) error {
 		return nil
 	}
 
-	return renderAnnotationsList(result)
+	return renderAnnotationsList(result, opts)
 }
 
-func renderAnnotationsList(result map[string]any) error {
+// sortAnnotations returns the annotations ordered by opts.sortBy. Ties are
+// broken by ID so the order is stable. Dates are compared as strings, which
+// orders the API's "yyyy-mm-dd hh:mm:ss" timestamps chronologically.
+func sortAnnotations(annotations []any, opts annotationsListOptions) []any {
+	result := make([]any, len(annotations))
+	copy(result, annotations)
+
+	less := func(a, b any) bool {
+		ma, _ := a.(map[string]any)
+		mb, _ := b.(map[string]any)
+		if opts.sortBy == "date" {
+			da, _ := ma["date"].(string)
+			db, _ := mb["date"].(string)
+			if da != db {
+				return da < db
+			}
+		}
+		ia, _ := output.NumericValue(ma["id"])
+		ib, _ := output.NumericValue(mb["id"])
+		return ia < ib
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if opts.desc {
+			return less(result[j], result[i])
+		}
+		return less(result[i], result[j])
+	})
+	return result
+}
+
+func renderAnnotationsList(result map[string]any, opts annotationsListOptions) error {
 	s := getIO()
 
 	resultsRaw, ok := result["results"].([]any)
 	if !ok || len(resultsRaw) == 0 {
-		s.Printf("No annotations found.\n")
-		return nil
+		return emptyResult(s, "No annotations found.")
 	}
+	resultsRaw = sortAnnotations(resultsRaw, opts)
 
 	headers := []string{"ID", "DATE", "DESCRIPTION"}
 	rows := make([][]string, 0, len(resultsRaw))
@@ -188,5 +257,5 @@ func runAnnotationsGet(cmd *cobra.Command, annotationID int) error {
 	}
 
 	// Render single annotation as a simple table.
-	return renderAnnotationsList(result)
+	return renderAnnotationsList(result, annotationsListOptions{sortBy: "date"})
 }
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	iolib "io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+
+	"github.com/aviadshiber/mp/internal/cache"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	rootCmd.AddCommand(newCacheCmd())
+}
+
+func newCacheCmd() *cobra.Command {
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the local metadata cache",
+		Long: `Manage the on-disk cache used by metadata commands such as "query funnels list",
+"query custom-events list", and "cohorts list". Analytics queries and exports
+are never cached.
+
+The cache is off unless enabled with --cache, MP_CACHE=true, or
+"mp config set cache true". Cached responses then expire after --cache-ttl
+(default 5m). Use --no-cache on any command to bypass an enabled cache.`,
+	}
+
+	cacheCmd.AddCommand(newCacheClearCmd())
+	return cacheCmd
+}
+
+func newCacheClearCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "clear",
+		Short: "Remove all cached responses",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := cacheDir()
+			if err != nil {
+				return err
+			}
+			if err := cache.Clear(dir); err != nil {
+				return err
+			}
+			s := getIO()
+			s.Printf("%s Cleared cache %s\n", s.Success(""), dir)
+			return nil
+		},
+	}
+}
+
+// cacheDir returns the cache directory, which lives next to the config file.
+func cacheDir() (string, error) {
+	path, err := configFilePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(path), "cache"), nil
+}
+
+// cachedResponse returns the body for a metadata request, serving it from the
+// on-disk cache when a fresh entry exists and calling fetch otherwise.
+// endpoint and params identify the request together with the region. Only
+// commands returning slow-changing metadata should use this.
+//
+// When conditional_requests is enabled and an expired entry has validators,
+// fetch is passed If-None-Match/If-Modified-Since headers, and a 304 response
+// renews the cached entry instead of downloading it again.
+func cachedResponse(endpoint string, params url.Values, fetch func(h http.Header) (*http.Response, error)) ([]byte, error) {
+	ttl := viper.GetDuration("cache_ttl")
+	if !viper.GetBool("cache") || cfgNoCache || ttl <= 0 {
+		return fetchBody(fetch, nil)
+	}
+
+	dir, err := cacheDir()
+	if err != nil {
+		return fetchBody(fetch, nil)
+	}
+	c := cache.New(dir, ttl)
+	key := resolveRegion() + " " + endpoint + "?" + params.Encode()
+
+	if body, ok := c.Get(key); ok {
+		return body, nil
+	}
+
+	var h http.Header
+	stale, validators, hasStale := c.GetStale(key)
+	if hasStale && !validators.IsZero() && viper.GetBool("conditional_requests") {
+		h = http.Header{}
+		if validators.ETag != "" {
+			h.Set("If-None-Match", validators.ETag)
+		}
+		if validators.LastModified != "" {
+			h.Set("If-Modified-Since", validators.LastModified)
+		}
+	}
+
+	resp, err := fetch(h)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotModified && h != nil {
+		drainBody(resp)
+		_ = c.Touch(key)
+		return stale, nil
+	}
+
+	body, err := readResponseBody(resp.Body, resp.StatusCode)
+	if err != nil {
+		return nil, err
+	}
+	// A failed cache write only costs a refetch next time.
+	_ = c.SetWithValidators(key, body, cache.Validators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	})
+	return body, nil
+}
+
+// fetchBody calls fetch with h and reads the response body.
+func fetchBody(fetch func(h http.Header) (*http.Response, error), h http.Header) ([]byte, error) {
+	resp, err := fetch(h)
+	if err != nil {
+		return nil, err
+	}
+	return readResponseBody(resp.Body, resp.StatusCode)
+}
+
+// drainBody discards and closes an unused response body so the connection
+// can be reused.
+func drainBody(resp *http.Response) {
+	_, _ = iolib.Copy(iolib.Discard, resp.Body)
+	resp.Body.Close()
+}
@@ -3,8 +3,10 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
 	"sort"
+	"strings"
 
 	"github.com/aviadshiber/mp/internal/client"
 	"github.com/aviadshiber/mp/internal/output"
@@ -22,57 +24,218 @@ func newCohortsCmd() *cobra.Command {
 		Long:  "List and inspect Mixpanel cohorts.",
 	}
 
+	setRequestTimeout(cohortsCmd, metadataRequestTimeout)
+
 	cohortsCmd.AddCommand(newCohortsListCmd())
+	cohortsCmd.AddCommand(newCohortsGetCmd())
+	cohortsCmd.AddCommand(newCohortsMembersCmd())
 	return cohortsCmd
 }
 
 func newCohortsListCmd() *cobra.Command {
+	var opts cohortsListOptions
+
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all cohorts in the project",
-		Long:  "List all cohorts in the current project with their IDs, names, counts, and descriptions.",
+		Long: `List all cohorts in the current project with their IDs, names, counts, and descriptions.
+
+The table is sorted by ID unless --sort is given. --json returns the full list
+in API order, unless --sort, --desc, or --min-count is set explicitly, in which
+case the JSON is sorted and filtered the same way as the table.`,
 		Example: `  # List all cohorts
   mp cohorts list
 
+  # Largest cohorts first, hiding empty ones
+  mp cohorts list --sort count --desc --min-count 1
+
   # JSON output
   mp cohorts list --json
 
   # Filter with jq
-  mp cohorts list --json --jq '.[].name'`,
+  mp cohorts list --jq '.[].name'`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runCohortsList(cmd)
+			switch opts.sortBy {
+			case "id", "name", "count":
+			default:
+				return fmt.Errorf("invalid `--sort` %q; must be one of: id, name, count", opts.sortBy)
+			}
+			if opts.minCount < 0 {
+				return fmt.Errorf("`--min-count` must not be negative")
+			}
+			flags := cmd.Flags()
+			opts.applyToJSON = flags.Changed("sort") || flags.Changed("desc") || flags.Changed("min-count")
+			return runCohortsList(cmd, opts)
 		},
 	}
+
+	cmd.Flags().StringVar(&opts.sortBy, "sort", "id", "Sort by: id, name, count")
+	cmd.Flags().BoolVar(&opts.desc, "desc", false, "Sort in descending order")
+	cmd.Flags().IntVar(&opts.minCount, "min-count", 0, "Hide cohorts with fewer than N members")
+
 	return cmd
 }
 
-func runCohortsList(cmd *cobra.Command) error {
-	c, err := newClient()
+// cohortsListOptions controls the order and filtering of `cohorts list`.
+type cohortsListOptions struct {
+	sortBy   string // id, name, or count
+	desc     bool
+	minCount int
+	// applyToJSON sorts and filters JSON output too; otherwise JSON is
+	// returned as the API sent it.
+	applyToJSON bool
+}
+
+func newCohortsGetCmd() *cobra.Command {
+	var cohortID int
+
+	cmd := &cobra.Command{
+		Use:   "get",
+		Short: "Get a cohort and its definition by ID",
+		Long: `Get a single cohort by ID, including its filter and behavior definition.
+
+Mixpanel has no single-cohort endpoint, so the cohort is looked up in the
+/cohorts/list response. Summary fields are shown first, followed by every
+other field returned for the cohort; nested definitions are printed as JSON.`,
+		Example: `  # Show a cohort and its definition
+  mp cohorts get --id 67890
+
+  # Full cohort object as JSON
+  mp cohorts get --id 67890 --json
+
+  # Extract just the filter definition
+  mp cohorts get --id 67890 --jq '.filters'`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cohortID <= 0 {
+				return fmt.Errorf("`--id` must be a positive cohort ID")
+			}
+			return runCohortsGet(cmd, cohortID)
+		},
+	}
+
+	cmd.Flags().IntVar(&cohortID, "id", 0, "Cohort ID (required)")
+	_ = cmd.MarkFlagRequired("id")
+
+	return cmd
+}
+
+func newCohortsMembersCmd() *cobra.Command {
+	var (
+		cohortID   int
+		properties string
+		limit      int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "members",
+		Short: "List the user profiles in a cohort",
+		Long: `List the user profiles that belong to a cohort. This is equivalent to
+"mp profiles query --cohort-id N" and paginates through all members unless a
+--limit is specified.`,
+		Example: `  # List members of a cohort
+  mp cohorts members --cohort-id 67890
+
+  # Show specific properties for the first 100 members
+  mp cohorts members --cohort-id 67890 --properties '$email,$name' --limit 100
+
+  # JSON output
+  mp cohorts members --cohort-id 67890 --json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cohortID <= 0 {
+				return fmt.Errorf("`--cohort-id` must be a positive cohort ID")
+			}
+			return runProfilesQuery(cmd, "", "", nil, properties, cohortID, limit, maxEngagePageSize, false)
+		},
+	}
+
+	cmd.Flags().IntVar(&cohortID, "cohort-id", 0, "Cohort ID (required)")
+	cmd.Flags().StringVar(&properties, "properties", "", "Comma-separated output property names (e.g., $email,$name)")
+	cmd.Flags().IntVar(&limit, "limit", 0, "Maximum total members to fetch (0 = all)")
+
+	_ = cmd.MarkFlagRequired("cohort-id")
+
+	setRequestTimeout(cmd, 0)
+
+	return cmd
+}
+
+func runCohortsList(cmd *cobra.Command, opts cohortsListOptions) error {
+	cohorts, err := fetchCohorts()
 	if err != nil {
 		return err
 	}
 
-	params := url.Values{}
-	if err := addProjectID(params); err != nil {
+	if opts.applyToJSON {
+		cohorts = sortCohorts(cohorts, opts)
+	}
+	handled, err := handleJSONOutput(cmd, cohorts)
+	if err != nil {
 		return err
 	}
+	if handled {
+		return nil
+	}
 
-	resp, err := c.Post(client.APIFamilyQuery, "/cohorts/list", params)
-	if err != nil {
-		return fmt.Errorf("listing cohorts: %w", err)
+	return renderCohortsList(sortCohorts(cohorts, opts))
+}
+
+// sortCohorts returns the cohorts with at least opts.minCount members, ordered
+// by opts.sortBy. Ties are broken by ID so the order is stable. Counts that
+// are missing or not numeric are treated as zero.
+func sortCohorts(cohorts []map[string]any, opts cohortsListOptions) []map[string]any {
+	result := make([]map[string]any, 0, len(cohorts))
+	for _, c := range cohorts {
+		if count, _ := output.NumericValue(c["count"]); count >= float64(opts.minCount) {
+			result = append(result, c)
+		}
 	}
 
-	body, err := readResponseBody(resp.Body, resp.StatusCode)
+	less := func(a, b map[string]any) bool {
+		switch opts.sortBy {
+		case "name":
+			na, _ := a["name"].(string)
+			nb, _ := b["name"].(string)
+			if na != nb {
+				return strings.ToLower(na) < strings.ToLower(nb)
+			}
+		case "count":
+			ca, _ := output.NumericValue(a["count"])
+			cb, _ := output.NumericValue(b["count"])
+			if ca != cb {
+				return ca < cb
+			}
+		}
+		ia, _ := output.NumericValue(a["id"])
+		ib, _ := output.NumericValue(b["id"])
+		return ia < ib
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if opts.desc {
+			return less(result[j], result[i])
+		}
+		return less(result[i], result[j])
+	})
+	return result
+}
+
+func runCohortsGet(cmd *cobra.Command, cohortID int) error {
+	cohorts, err := fetchCohorts()
 	if err != nil {
 		return err
 	}
 
-	var cohorts []map[string]any
-	if err := json.Unmarshal(body, &cohorts); err != nil {
-		return fmt.Errorf("parsing cohorts response: %w", err)
+	var cohort map[string]any
+	for _, c := range cohorts {
+		if id, ok := c["id"].(float64); ok && int(id) == cohortID {
+			cohort = c
+			break
+		}
+	}
+	if cohort == nil {
+		return fmt.Errorf("cohort %d not found in project", cohortID)
 	}
 
-	handled, err := handleJSONOutput(cmd, cohorts)
+	handled, err := handleJSONOutput(cmd, cohort)
 	if err != nil {
 		return err
 	}
@@ -80,24 +243,100 @@ func runCohortsList(cmd *cobra.Command) error {
 		return nil
 	}
 
-	return renderCohortsList(cohorts)
+	return renderCohortDetail(cohort)
+}
+
+// fetchCohorts returns every cohort in the current project.
+// Response shape: [{"id": 1, "name": "...", "count": 10, "created": "...", ...}]
+func fetchCohorts() ([]map[string]any, error) {
+	c, err := newClient()
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	if err := addProjectID(params); err != nil {
+		return nil, err
+	}
+
+	body, err := cachedResponse("/cohorts/list", params, func(h http.Header) (*http.Response, error) {
+		resp, err := c.PostWithHeader(client.APIFamilyQuery, "/cohorts/list", params, h)
+		if err != nil {
+			return nil, fmt.Errorf("listing cohorts: %w", err)
+		}
+		return resp, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var cohorts []map[string]any
+	if err := json.Unmarshal(body, &cohorts); err != nil {
+		return nil, fmt.Errorf("parsing cohorts response: %w", err)
+	}
+	return cohorts, nil
 }
 
+// cohortSummaryFields are shown first, in this order, by renderCohortDetail.
+var cohortSummaryFields = []string{"id", "name", "count", "created", "description"}
+
+// renderCohortDetail renders a single cohort as a FIELD/VALUE table. Summary
+// fields come first; remaining fields (filters, behaviors, ...) follow in
+// name order with nested values printed as compact JSON.
+func renderCohortDetail(cohort map[string]any) error {
+	s := getIO()
+
+	rows := make([][]string, 0, len(cohort))
+	seen := make(map[string]bool, len(cohortSummaryFields))
+	for _, key := range cohortSummaryFields {
+		seen[key] = true
+		if v, ok := cohort[key]; ok {
+			rows = append(rows, []string{key, cohortFieldValue(v)})
+		}
+	}
+
+	rest := make([]string, 0, len(cohort))
+	for key := range cohort {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	for _, key := range rest {
+		rows = append(rows, []string{key, cohortFieldValue(cohort[key])})
+	}
+
+	output.PrintTable(s.Out, []string{"FIELD", "VALUE"}, rows, s.IsTerminal())
+	return nil
+}
+
+func cohortFieldValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return output.FormatNumber(val)
+	case map[string]any, []any:
+		b, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprintf("%v", val)
+		}
+		return string(b)
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+// renderCohortsList renders cohorts as a table in the order given.
 func renderCohortsList(cohorts []map[string]any) error {
 	s := getIO()
 
 	if len(cohorts) == 0 {
-		s.Printf("No cohorts found.\n")
-		return nil
+		return emptyResult(s, "No cohorts found.")
 	}
 
-	// Sort by ID for consistent output.
-	sort.Slice(cohorts, func(i, j int) bool {
-		idI, _ := cohorts[i]["id"].(float64)
-		idJ, _ := cohorts[j]["id"].(float64)
-		return idI < idJ
-	})
-
 	headers := []string{"ID", "NAME", "COUNT", "CREATED", "DESCRIPTION"}
 	rows := make([][]string, 0, len(cohorts))
 
@@ -112,5 +351,6 @@ func renderCohortsList(cohorts []map[string]any) error {
 	}
 
 	output.PrintTable(s.Out, headers, rows, s.IsTerminal())
+	s.Footer("%s", pluralize(len(rows), "cohort"))
 	return nil
 }
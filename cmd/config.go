@@ -3,23 +3,31 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/aviadshiber/mp/internal/client"
 	"github.com/aviadshiber/mp/internal/config"
 	"github.com/aviadshiber/mp/internal/output"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 func newConfigCmd() *cobra.Command {
 	configCmd := &cobra.Command{
 		Use:   "config",
 		Short: "Manage mp configuration",
-		Long: `Get, set, and list configuration values stored in ~/.config/mp/config.yaml.
+		Long: `Get, set, and list configuration values stored in $XDG_CONFIG_HOME/mp/config.yaml
+(default ~/.config/mp/config.yaml). Use --config or MP_CONFIG_FILE to select a
+different file.
 
-Valid keys: project_id, region, service_account, service_secret`,
+Valid keys: project_id, region, auth_mode, service_account, service_secret,
+api_secret, retry_jitter, clamp_page_size, concurrency,
+requests_per_second, conditional_requests, max_response_bytes, ca_cert,
+cache`,
 	}
 
 	configCmd.AddCommand(newConfigSetCmd())
 	configCmd.AddCommand(newConfigGetCmd())
 	configCmd.AddCommand(newConfigListCmd())
+	configCmd.AddCommand(newConfigValidateCmd())
 
 	return configCmd
 }
@@ -30,7 +38,7 @@ func newConfigSetCmd() *cobra.Command {
 		Short: "Set a configuration value",
 		Args:  cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg, err := config.New()
+			cfg, err := openConfig()
 			if err != nil {
 				return err
 			}
@@ -49,12 +57,41 @@ func newConfigSetCmd() *cobra.Command {
 }
 
 func newConfigGetCmd() *cobra.Command {
-	return &cobra.Command{
+	var (
+		all         bool
+		showSecrets bool
+	)
+
+	cmd := &cobra.Command{
 		Use:   "get <key>",
 		Short: "Get a configuration value",
-		Args:  cobra.ExactArgs(1),
+		Long: `Get a configuration value from the config file.
+
+With --all, print every setting as mp resolves it after merging the config file,
+environment variables (including MP_TOKEN), flags, and defaults; the config file
+in use is printed to stderr after the table. Unlike "mp config list", which
+shows only values set in the file, this is what commands actually run with.
+Secrets are masked unless --show-secrets is given.`,
+		Example: `  # One value from the config file
+  mp config get region
+
+  # Every resolved setting, for scripts
+  mp config get --all --json`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if all {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg, err := config.New()
+			if showSecrets && !all {
+				return fmt.Errorf("`--show-secrets` requires `--all`")
+			}
+			if all {
+				return runConfigGetAll(cmd, showSecrets)
+			}
+
+			cfg, err := openConfig()
 			if err != nil {
 				return err
 			}
@@ -69,6 +106,72 @@ func newConfigGetCmd() *cobra.Command {
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Print every resolved setting after merging the config file, environment, and flags")
+	cmd.Flags().BoolVar(&showSecrets, "show-secrets", false, "Print secrets in full instead of masked (with --all)")
+
+	return cmd
+}
+
+// runConfigGetAll prints the effective value of every known key, plus the
+// config file in use.
+func runConfigGetAll(cmd *cobra.Command, showSecrets bool) error {
+	path, err := configFilePath()
+	if err != nil {
+		return err
+	}
+	resolved := resolvedConfig()
+	if !showSecrets {
+		for key, val := range resolved {
+			if val != "" && config.IsSensitive(key) {
+				resolved[key] = config.Mask(val)
+			}
+		}
+	}
+
+	handled, err := handleJSONOutput(cmd, map[string]any{"config_file": path, "values": output.NormalizeJSON(resolved)})
+	if err != nil {
+		return err
+	}
+	if handled {
+		return nil
+	}
+
+	s := getIO()
+	headers := []string{"KEY", "VALUE"}
+	rows := make([][]string, 0, len(resolved))
+	for _, key := range config.KnownKeyNames() {
+		rows = append(rows, []string{key, resolved[key]})
+	}
+	output.PrintTable(s.Out, headers, rows, s.IsTerminal())
+	// The footer goes to stderr so piped TSV output stays clean.
+	s.Errorf("\n%s %s\n", s.Muted("Config file:"), path)
+	return nil
+}
+
+// resolvedConfig returns the value commands use for every known key, with
+// the same precedence they apply: flags, then environment variables, then
+// the config file, then defaults. Credentials reflect MP_TOKEN when it is
+// set, and keys with no value map to "".
+func resolvedConfig() map[string]string {
+	resolved := make(map[string]string, len(config.KnownKeyNames()))
+	for _, key := range config.KnownKeyNames() {
+		resolved[key] = viper.GetString(key)
+	}
+
+	resolved[config.KeyProjectID] = resolveProjectID()
+	resolved[config.KeyRegion] = resolveRegion()
+
+	if auth, err := resolveAuth(); err == nil {
+		resolved[config.KeyAuthMode] = auth.Mode
+		if auth.Mode == client.AuthModeAPISecret {
+			resolved[config.KeyAPISecret] = auth.Secret
+		} else {
+			resolved[config.KeyServiceAccount] = auth.Username
+			resolved[config.KeyServiceSecret] = auth.Secret
+		}
+	}
+	return resolved
 }
 
 func newConfigListCmd() *cobra.Command {
@@ -77,7 +180,7 @@ func newConfigListCmd() *cobra.Command {
 		Short: "List all configuration values",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg, err := config.New()
+			cfg, err := openConfig()
 			if err != nil {
 				return err
 			}
@@ -85,8 +188,12 @@ func newConfigListCmd() *cobra.Command {
 			entries := cfg.List()
 			s := getIO()
 
-			if jsonOutputRequested(cmd) {
-				return output.PrintJSON(s.Out, entries)
+			handled, err := handleJSONOutput(cmd, entries)
+			if err != nil {
+				return err
+			}
+			if handled {
+				return nil
 			}
 
 			if len(entries) == 0 {
@@ -107,3 +214,71 @@ func newConfigListCmd() *cobra.Command {
 		},
 	}
 }
+
+func newConfigValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate [path]",
+		Short: "Check a config file for problems without applying it",
+		Long: `Check a config file for unknown keys, invalid values, and missing credentials
+for its auth_mode. Defaults to the active config file. Each problem is printed
+on its own line as "path:line: key: message", and the command exits non-zero
+if any are found.`,
+		Example: `  # Validate the active config
+  mp config validate
+
+  # Validate a templated file in CI
+  mp config validate ./deploy/mp-config.yaml --json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := ""
+			if len(args) == 1 {
+				path = args[0]
+			} else {
+				p, err := configFilePath()
+				if err != nil {
+					return err
+				}
+				path = p
+			}
+
+			problems, err := config.Validate(path)
+			if err != nil {
+				return err
+			}
+
+			if problems == nil {
+				problems = []config.Problem{}
+			}
+			// Problems are converted to generic JSON values so --jq can
+			// query them.
+			report := map[string]any{
+				"file":     path,
+				"valid":    len(problems) == 0,
+				"problems": output.NormalizeJSON(problems),
+			}
+			handled, err := handleJSONOutput(cmd, report)
+			if err != nil {
+				return err
+			}
+
+			s := getIO()
+			if !handled {
+				for _, p := range problems {
+					loc := path
+					if p.Line > 0 {
+						loc = fmt.Sprintf("%s:%d", path, p.Line)
+					}
+					s.Errorf("%s: %s: %s\n", loc, p.Key, p.Message)
+				}
+				if len(problems) == 0 {
+					s.Printf("%s %s is valid\n", s.Success(""), path)
+				}
+			}
+
+			if len(problems) > 0 {
+				return fmt.Errorf("%s found in %s", pluralize(len(problems), "problem"), path)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aviadshiber/mp/internal/client"
+	"github.com/aviadshiber/mp/internal/output"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(newDataDeletionCmd())
+}
+
+func newDataDeletionCmd() *cobra.Command {
+	dataDeletionCmd := &cobra.Command{
+		Use:   "data-deletion",
+		Short: "Submit and track GDPR/CCPA data deletion tasks",
+		Long: `Submit and track bulk data deletion tasks through the Mixpanel GDPR API.
+
+Deletion tasks run asynchronously. Use "mp data-deletion create" to submit a
+task and "mp data-deletion status" to poll its progress.`,
+	}
+
+	dataDeletionCmd.AddCommand(newDataDeletionCreateCmd())
+	dataDeletionCmd.AddCommand(newDataDeletionStatusCmd())
+	return dataDeletionCmd
+}
+
+func newDataDeletionCreateCmd() *cobra.Command {
+	var (
+		distinctIDs    string
+		complianceType string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Submit a data deletion task",
+		Long: `Submit a data deletion task for one or more distinct IDs. Prints the task ID,
+which can be passed to "mp data-deletion status".`,
+		Example: `  # Delete data for two users under GDPR
+  mp data-deletion create --distinct-ids "user1,user2"
+
+  # CCPA deletion
+  mp data-deletion create --distinct-ids "user1" --compliance-type CCPA
+
+  # JSON output
+  mp data-deletion create --distinct-ids "user1" --json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDataDeletionCreate(cmd, distinctIDs, complianceType)
+		},
+	}
+
+	cmd.Flags().StringVar(&distinctIDs, "distinct-ids", "", "Comma-separated distinct IDs to delete (required)")
+	cmd.Flags().StringVar(&complianceType, "compliance-type", "GDPR", "Compliance type: GDPR, CCPA")
+
+	_ = cmd.MarkFlagRequired("distinct-ids")
+
+	return cmd
+}
+
+func runDataDeletionCreate(cmd *cobra.Command, distinctIDs, complianceType string) error {
+	ids := splitCSV(distinctIDs)
+	if len(ids) == 0 {
+		return fmt.Errorf("`--distinct-ids` must specify at least one ID")
+	}
+
+	complianceType = strings.ToUpper(complianceType)
+	if complianceType != "GDPR" && complianceType != "CCPA" {
+		return fmt.Errorf("invalid `--compliance-type` %q; must be one of: GDPR, CCPA", complianceType)
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	if err := addProjectID(params); err != nil {
+		return err
+	}
+
+	payload := map[string]any{
+		"distinct_ids":    ids,
+		"compliance_type": complianceType,
+	}
+
+	resp, err := c.PostJSON(client.APIFamilyApp, "/data-deletions/v3.0/", params, payload)
+	if err != nil {
+		return fmt.Errorf("creating data deletion task: %w", err)
+	}
+
+	body, err := readResponseBody(resp.Body, resp.StatusCode)
+	if err != nil {
+		return err
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("parsing data deletion response: %w", err)
+	}
+	if err := checkAPIError(result); err != nil {
+		return err
+	}
+
+	handled, err := handleJSONOutput(cmd, result)
+	if err != nil {
+		return err
+	}
+	if handled {
+		return nil
+	}
+
+	s := getIO()
+	results, _ := result["results"].(map[string]any)
+	taskID, _ := results["task_id"].(string)
+	if taskID == "" {
+		return output.PrintJSON(s.Out, result)
+	}
+
+	s.Printf("%s Submitted %s deletion task for %d distinct IDs\n", s.Success(""), complianceType, len(ids))
+	s.Printf("Task ID: %s\n", taskID)
+	return nil
+}
+
+func newDataDeletionStatusCmd() *cobra.Command {
+	var taskID string
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the status of a data deletion task",
+		Long:  "Show the status of a previously submitted data deletion task.",
+		Example: `  # Check a task's status
+  mp data-deletion status --task-id 1a2b3c
+
+  # JSON output
+  mp data-deletion status --task-id 1a2b3c --json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDataDeletionStatus(cmd, taskID)
+		},
+	}
+
+	cmd.Flags().StringVar(&taskID, "task-id", "", "Deletion task ID (required)")
+	_ = cmd.MarkFlagRequired("task-id")
+
+	return cmd
+}
+
+func runDataDeletionStatus(cmd *cobra.Command, taskID string) error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	if err := addProjectID(params); err != nil {
+		return err
+	}
+
+	path := "/data-deletions/v3.0/" + url.PathEscape(taskID)
+	resp, err := c.Get(client.APIFamilyApp, path, params)
+	if err != nil {
+		return fmt.Errorf("getting data deletion status: %w", err)
+	}
+
+	body, err := readResponseBody(resp.Body, resp.StatusCode)
+	if err != nil {
+		return err
+	}
+
+	var result map[string]any
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("parsing data deletion status response: %w", err)
+	}
+	if err := checkAPIError(result); err != nil {
+		return err
+	}
+
+	handled, err := handleJSONOutput(cmd, result)
+	if err != nil {
+		return err
+	}
+	if handled {
+		return nil
+	}
+
+	return renderDataDeletionStatus(taskID, result)
+}
+
+// renderDataDeletionStatus renders a deletion task as a single-row table.
+// Response shape: {"status": "ok", "results": {"status": "PENDING", "created": "...", ...}}
+func renderDataDeletionStatus(taskID string, result map[string]any) error {
+	s := getIO()
+
+	task, ok := result["results"].(map[string]any)
+	if !ok {
+		return output.PrintJSON(s.Out, result)
+	}
+
+	status, _ := task["status"].(string)
+	created, _ := task["created"].(string)
+	complianceType, _ := task["compliance_type"].(string)
+
+	idCount := ""
+	if ids, ok := task["distinct_ids"].([]any); ok {
+		idCount = fmt.Sprintf("%d", len(ids))
+	}
+
+	headers := []string{"TASK ID", "STATUS", "COMPLIANCE TYPE", "DISTINCT IDS", "CREATED"}
+	rows := [][]string{{taskID, status, complianceType, idCount, created}}
+
+	output.PrintTable(s.Out, headers, rows, s.IsTerminal())
+	return nil
+}
@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/aviadshiber/mp/internal/client"
+	"github.com/aviadshiber/mp/internal/config"
+	"github.com/aviadshiber/mp/internal/output"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(newDoctorCmd())
+}
+
+// doctorDialTimeout bounds the network reachability check.
+const doctorDialTimeout = 5 * time.Second
+
+// Statuses of a doctor check.
+const (
+	checkPass = "pass"
+	checkFail = "fail"
+	checkSkip = "skip"
+)
+
+// doctorCheck is the outcome of one `mp doctor` check.
+type doctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// doctorReport is the bundle `mp doctor --json` prints. It never contains
+// secrets; credentials are shown masked.
+type doctorReport struct {
+	Version    string        `json:"version"`
+	Commit     string        `json:"commit"`
+	OS         string        `json:"os"`
+	Arch       string        `json:"arch"`
+	GoVersion  string        `json:"go_version"`
+	ConfigFile string        `json:"config_file"`
+	Region     string        `json:"region"`
+	ProjectID  string        `json:"project_id"`
+	Checks     []doctorCheck `json:"checks"`
+	OK         bool          `json:"ok"`
+}
+
+func newDoctorCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose the mp installation, configuration, and API access",
+		Long: `Run a series of checks useful when something does not work: the config file
+and its validity, the resolved region and project, the credentials in use, a
+network connection to the region's Mixpanel host, and an authenticated API
+call that lists one event name.
+
+Each check is reported as pass, fail, or skip (when an earlier check it
+depends on failed). Credentials are always masked, so the output, including
+the --json bundle, is safe to paste into an issue. The command exits non-zero
+if any check fails.`,
+		Example: `  # Check everything
+  mp doctor
+
+  # Structured report to attach to a bug report
+  mp doctor --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDoctor(cmd)
+		},
+	}
+}
+
+func runDoctor(cmd *cobra.Command) error {
+	report := doctorReport{
+		Version:   versionInfo.version,
+		Commit:    versionInfo.commit,
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		GoVersion: runtime.Version(),
+		Region:    resolveRegion(),
+		ProjectID: resolveProjectID(),
+	}
+
+	add := func(name, status, format string, a ...any) {
+		report.Checks = append(report.Checks, doctorCheck{Name: name, Status: status, Detail: fmt.Sprintf(format, a...)})
+	}
+
+	add("platform", checkPass, "mp %s on %s/%s (%s)", report.Version, report.OS, report.Arch, report.GoVersion)
+
+	if path, err := configFilePath(); err != nil {
+		add("config", checkFail, "%v", err)
+	} else {
+		report.ConfigFile = path
+		status, detail := doctorConfig(path)
+		add("config", status, "%s", detail)
+	}
+
+	if report.ProjectID == "" {
+		add("project", checkFail, "no project ID; set via --project-id, MP_PROJECT_ID, or mp config set project_id <id>")
+	} else {
+		add("project", checkPass, "project %s in region %s", report.ProjectID, report.Region)
+	}
+
+	c, err := newClient()
+	if err != nil {
+		add("credentials", checkFail, "%v", err)
+	} else {
+		add("credentials", checkPass, "%s", describeAuth())
+	}
+
+	reachable := false
+	host, err := doctorHost(report.Region)
+	if err != nil {
+		add("network", checkFail, "%v", err)
+	} else {
+		start := time.Now()
+		conn, err := net.DialTimeout("tcp", host, doctorDialTimeout)
+		if err != nil {
+			add("network", checkFail, "cannot reach %s: %v", host, err)
+		} else {
+			_ = conn.Close()
+			reachable = true
+			add("network", checkPass, "connected to %s in %s", host, time.Since(start).Round(time.Millisecond))
+		}
+	}
+
+	switch {
+	case c == nil || report.ProjectID == "" || !reachable:
+		add("api", checkSkip, "requires a project, credentials, and network access")
+	default:
+		if err := doctorAPICall(c); err != nil {
+			add("api", checkFail, "%v", err)
+		} else {
+			add("api", checkPass, "authenticated request to /events/names succeeded")
+		}
+	}
+
+	failed := 0
+	for _, check := range report.Checks {
+		if check.Status == checkFail {
+			failed++
+		}
+	}
+	report.OK = failed == 0
+
+	handled, err := handleJSONOutput(cmd, report)
+	if err != nil {
+		return err
+	}
+	if !handled {
+		renderDoctorReport(report.Checks)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%s failed", pluralize(failed, "check"))
+	}
+	return nil
+}
+
+// doctorConfig reports whether the config file at path exists and is valid.
+// A missing file is not a failure, since flags and env vars can supply
+// everything.
+func doctorConfig(path string) (status, detail string) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return checkPass, fmt.Sprintf("%s does not exist; using flags and environment only", path)
+	}
+	problems, err := config.Validate(path)
+	if err != nil {
+		return checkFail, err.Error()
+	}
+	if len(problems) > 0 {
+		p := problems[0]
+		return checkFail, fmt.Sprintf("%s: %s found (first: %s: %s); run mp config validate",
+			path, pluralize(len(problems), "problem"), p.Key, p.Message)
+	}
+	return checkPass, path + " is valid"
+}
+
+// describeAuth describes the credentials newClient uses, with the identifier
+// masked and the secret omitted.
+func describeAuth() string {
+	auth, err := resolveAuth()
+	if err != nil {
+		return err.Error()
+	}
+	source := "config"
+	if os.Getenv("MP_TOKEN") != "" {
+		source = "MP_TOKEN"
+	}
+	if auth.Mode == client.AuthModeAPISecret {
+		return fmt.Sprintf("api_secret %s from %s", config.Mask(auth.Secret), source)
+	}
+	return fmt.Sprintf("service account %s from %s", config.Mask(auth.Username), source)
+}
+
+// doctorHost returns the host:port of the query API for region.
+func doctorHost(region string) (string, error) {
+	base, err := client.ResolveURL(client.APIFamilyQuery, region)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(u.Hostname(), "443"), nil
+}
+
+// doctorAPICall makes the cheapest authenticated request mp knows: listing a
+// single event name. It bypasses the metadata cache so the credentials are
+// actually exercised.
+func doctorAPICall(c *client.Client) error {
+	params := url.Values{}
+	if err := addProjectID(params); err != nil {
+		return err
+	}
+	params.Set("type", "general")
+	params.Set("limit", "1")
+
+	resp, err := c.Get(client.APIFamilyQuery, "/events/names", params)
+	if err != nil {
+		return err
+	}
+	_, err = readResponseBody(resp.Body, resp.StatusCode)
+	return err
+}
+
+// renderDoctorReport prints one row per check with a pass/fail mark.
+func renderDoctorReport(checks []doctorCheck) {
+	s := getIO()
+
+	headers := []string{"CHECK", "STATUS", "DETAIL"}
+	rows := make([][]string, len(checks))
+	for i, check := range checks {
+		status := s.Success("✓ " + check.Status)
+		switch check.Status {
+		case checkFail:
+			status = s.Failure("✗ " + check.Status)
+		case checkSkip:
+			status = s.Muted("- " + check.Status)
+		}
+		rows[i] = []string{check.Name, status, check.Detail}
+	}
+	output.PrintTable(s.Out, headers, rows, s.IsTerminal())
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aviadshiber/mp/internal/iostreams"
+	"github.com/aviadshiber/mp/internal/output"
+)
+
+// Exit codes returned by the mp binary. Scripts can rely on these values.
+const (
+	ExitOK          = 0 // Success.
+	ExitError       = 1 // Unclassified failure (network, parsing, usage).
+	ExitEmpty       = 2 // The query succeeded but returned no data (--fail-on-empty).
+	ExitAuthError   = 3 // The API rejected the credentials (HTTP 401/403).
+	ExitClientError = 4 // The API rejected the request (other HTTP 4xx).
+	ExitServerError = 5 // The API failed (HTTP 5xx).
+)
+
+// errEmptyResult is returned when a command finds no data and --fail-on-empty
+// is set.
+var errEmptyResult = errors.New("no data returned")
+
+// httpStatusError is an API failure carrying the HTTP status code so it can
+// be mapped to an exit code.
+type httpStatusError struct {
+	status int
+	msg    string
+}
+
+func (e *httpStatusError) Error() string {
+	return e.msg
+}
+
+// ExitCode maps an error returned by Execute to a process exit code.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	if errors.Is(err, errEmptyResult) {
+		return ExitEmpty
+	}
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		switch {
+		case statusErr.status == http.StatusUnauthorized || statusErr.status == http.StatusForbidden:
+			return ExitAuthError
+		case statusErr.status >= 500:
+			return ExitServerError
+		case statusErr.status >= 400:
+			return ExitClientError
+		}
+	}
+	return ExitError
+}
+
+// emptyResult prints msg for a command that found no data. It returns
+// errEmptyResult when --fail-on-empty is set so the process exits with
+// ExitEmpty, and nil otherwise.
+func emptyResult(s *iostreams.IOStreams, msg string) error {
+	s.Printf("%s\n", msg)
+	if cfgFailOnEmpty {
+		return errEmptyResult
+	}
+	return nil
+}
+
+// isEmptyData reports whether an API response carries no data, for
+// --fail-on-empty with JSON output. A response is empty if it is null, an
+// empty list or object, or an object whose nested lists and objects are all
+// empty; scalar fields such as counts or status strings alone do not make it
+// non-empty. An object with only scalar fields is itself the data.
+func isEmptyData(data any) bool {
+	switch v := output.NormalizeJSON(data).(type) {
+	case nil:
+		return true
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		if len(v) == 0 {
+			return true
+		}
+		hasCollection := false
+		for _, field := range v {
+			switch field := output.NormalizeJSON(field).(type) {
+			case []any, map[string]any:
+				hasCollection = true
+				if !isEmptyData(field) {
+					return false
+				}
+			}
+		}
+		return hasCollection
+	default:
+		return false
+	}
+}
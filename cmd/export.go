@@ -2,9 +2,16 @@ package cmd
 
 import (
 	"bufio"
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"fmt"
+	iolib "io"
 	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/aviadshiber/mp/internal/client"
 	"github.com/aviadshiber/mp/internal/output"
@@ -19,20 +26,30 @@ func newExportCmd() *cobra.Command {
 	exportCmd := &cobra.Command{
 		Use:   "export",
 		Short: "Export raw data from Mixpanel",
-		Long:  "Export raw event data from your Mixpanel project.",
+		Long:  "Export raw event and profile data from your Mixpanel project.",
 	}
 
+	setRequestTimeout(exportCmd, 0)
+
 	exportCmd.AddCommand(newExportEventsCmd())
+	exportCmd.AddCommand(newExportProfilesCmd())
 	return exportCmd
 }
 
 func newExportEventsCmd() *cobra.Command {
 	var (
-		from  string
-		to    string
-		event string
-		where string
-		limit int
+		from       string
+		to         string
+		event      string
+		where      string
+		limit      int
+		outputFile string
+		compress   bool
+		csvOut     bool
+		columns    string
+		namesOnly  bool
+		countOnly  bool
+		selectProp string
 	)
 
 	cmd := &cobra.Command{
@@ -40,7 +57,38 @@ func newExportEventsCmd() *cobra.Command {
 		Short: "Export raw events as JSONL",
 		Long: `Export raw event data from Mixpanel. Returns one JSON object per line (JSONL)
 by default, which is ideal for piping to other tools. Use --json to collect all
-events into a JSON array instead.`,
+events into a JSON array instead.
+
+If the connection drops mid-stream, mp requests the export again from the day
+before the last event it received and skips events it already wrote, up to 3
+times. This assumes the export returns events in time order; events sharing the
+last timestamp may be written twice, so deduplicate on $insert_id when exact
+counts matter. Exports with --limit are not resumed.
+
+Use --output-file to write to a file instead of stdout, and --compress to gzip
+the output. Compressed output is a standard .jsonl.gz (or .json.gz with --json)
+stream readable by gunzip and zcat.
+
+Use --csv to flatten each event into a CSV row with "event", "time" (formatted
+as UTC "yyyy-mm-dd hh:mm:ss"), and one column per property. Without --columns,
+all events are held in memory so the full set of property names can be
+discovered before the header is written; for large exports pass --columns to
+stream rows with a fixed set of property columns instead. Nested property values
+are written as JSON.
+
+Use --select to keep only some properties of each event: records are written
+with "event", "properties.time", and the selected properties, as they stream.
+The trimming happens in mp, so the full records are still downloaded; it saves
+disk space and downstream processing, not bandwidth.
+
+Use --event-names-only to print just the distinct event names in the range,
+sorted, one per line (or as a JSON array with --json). The export is still
+streamed and filtered by --event and --where, but only the set of names is
+kept in memory.
+
+Use --count-only to print just the number of events that match --event,
+--where, and --limit (or {"count": N} with --json), without writing any event
+data.`,
 		Example: `  # Export all events for January 2024
   mp export events --from 2024-01-01 --to 2024-01-31
 
@@ -51,28 +99,74 @@ events into a JSON array instead.`,
   mp export events --from 2024-01-01 --to 2024-01-31 --where 'properties["country"]=="US"'
 
   # Export as JSON array with jq filtering
-  mp export events --from 2024-01-01 --to 2024-01-31 --json --jq '.[].event'
+  mp export events --from 2024-01-01 --to 2024-01-31 --jq '.[].event'
 
   # Limit the number of exported events
-  mp export events --from 2024-01-01 --to 2024-01-31 --limit 1000`,
+  mp export events --from 2024-01-01 --to 2024-01-31 --limit 1000
+
+  # Write a gzip-compressed JSONL file
+  mp export events --from 2024-01-01 --to 2024-01-31 \
+    --output-file events.jsonl.gz --compress
+
+  # Flat CSV with selected property columns, streamed
+  mp export events --from 2024-01-01 --to 2024-01-31 --csv \
+    --columns 'distinct_id,$browser,$city' --output-file events.csv
+
+  # Keep only a few properties of each event
+  mp export events --from 2024-01-01 --to 2024-01-31 --select 'distinct_id,$browser'
+
+  # Distinct event names seen in January
+  mp export events --from 2024-01-01 --to 2024-01-31 --event-names-only
+
+  # Count US purchases in January
+  mp export events --from 2024-01-01 --to 2024-01-31 --event Purchase \
+    --where 'properties["country"]=="US"' --count-only`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runExportEvents(cmd, from, to, event, where, limit)
+			if csvOut && jsonOutputRequested(cmd) {
+				return fmt.Errorf("`--csv` and `--json` cannot be used together")
+			}
+			if columns != "" && !csvOut {
+				return fmt.Errorf("`--columns` requires `--csv`")
+			}
+			if countOnly && (namesOnly || csvOut) {
+				return fmt.Errorf("`--count-only` cannot be combined with `--event-names-only` or `--csv`")
+			}
+			if namesOnly && csvOut {
+				return fmt.Errorf("`--event-names-only` and `--csv` cannot be used together")
+			}
+			if selectProp != "" && (csvOut || namesOnly || countOnly) {
+				return fmt.Errorf("`--select` cannot be combined with `--csv`, `--event-names-only`, or `--count-only`; use `--columns` to choose CSV columns")
+			}
+			opts := exportFormat{csv: csvOut, columns: splitCSV(columns), namesOnly: namesOnly, countOnly: countOnly, selected: splitCSV(selectProp)}
+			return runExportEvents(cmd, from, to, event, where, limit, outputFile, compress, opts)
 		},
 	}
 
-	cmd.Flags().StringVar(&from, "from", "", "Start date (yyyy-mm-dd, required)")
-	cmd.Flags().StringVar(&to, "to", "", "End date (yyyy-mm-dd, required)")
+	addDateRangeFlags(cmd, &from, &to, true)
 	cmd.Flags().StringVar(&event, "event", "", "Comma-separated event names to filter")
 	cmd.Flags().StringVar(&where, "where", "", "Filter expression (e.g., properties[\"country\"]==\"US\")")
 	cmd.Flags().IntVar(&limit, "limit", 0, "Maximum number of events to export (max 100000)")
-
-	_ = cmd.MarkFlagRequired("from")
-	_ = cmd.MarkFlagRequired("to")
+	cmd.Flags().StringVar(&outputFile, "output-file", "", "Write output to this file instead of stdout")
+	cmd.Flags().BoolVar(&compress, "compress", false, "Gzip-compress the output")
+	cmd.Flags().BoolVar(&csvOut, "csv", false, "Flatten events into CSV rows")
+	cmd.Flags().StringVar(&columns, "columns", "", "Comma-separated property columns for --csv (streams without buffering)")
+	cmd.Flags().StringVar(&selectProp, "select", "", "Comma-separated properties to keep in each event, besides event and time (trimmed client-side)")
+	cmd.Flags().BoolVar(&namesOnly, "event-names-only", false, "Print only the sorted distinct event names in the export")
+	cmd.Flags().BoolVar(&countOnly, "count-only", false, "Print only the number of matching events")
 
 	return cmd
 }
 
-func runExportEvents(cmd *cobra.Command, from, to, event, where string, limit int) error {
+// exportFormat selects how exported events are written.
+type exportFormat struct {
+	csv       bool     // flatten events into CSV rows
+	columns   []string // fixed property columns for CSV; discovered when empty
+	namesOnly bool     // print only the distinct event names
+	countOnly bool     // print only the number of events
+	selected  []string // properties to keep in JSON records; all when empty
+}
+
+func runExportEvents(cmd *cobra.Command, from, to, event, where string, limit int, outputFile string, compress bool, format exportFormat) error {
 	if limit < 0 || limit > 100000 {
 		return fmt.Errorf("--limit must be between 0 and 100000")
 	}
@@ -81,6 +175,7 @@ func runExportEvents(cmd *cobra.Command, from, to, event, where string, limit in
 	if err != nil {
 		return err
 	}
+	defer reportRetries(c)
 
 	params := url.Values{}
 	if err := addProjectID(params); err != nil {
@@ -100,24 +195,92 @@ func runExportEvents(cmd *cobra.Command, from, to, event, where string, limit in
 		params.Set("limit", fmt.Sprintf("%d", limit))
 	}
 
-	resp, err := c.Get(client.APIFamilyExport, "/export", params)
+	stream, err := newExportStream(params, func(params url.Values) (iolib.ReadCloser, error) {
+		resp, err := c.Get(client.APIFamilyExport, "/export", params)
+		if err != nil {
+			return nil, fmt.Errorf("requesting event export: %w", err)
+		}
+		if resp.StatusCode >= 400 {
+			_, err := readResponseBody(resp.Body, resp.StatusCode)
+			return nil, err
+		}
+		return resp.Body, nil
+	})
 	if err != nil {
-		return fmt.Errorf("requesting event export: %w", err)
+		return err
 	}
-	defer resp.Body.Close()
+	defer stream.Close()
 
-	if resp.StatusCode >= 400 {
-		body, _ := readResponseBody(resp.Body, resp.StatusCode)
-		_ = body // error already formatted by readResponseBody
-		return fmt.Errorf("API error (HTTP %d)", resp.StatusCode)
+	w, closeOutput, err := openExportOutput(outputFile, compress)
+	if err != nil {
+		return err
 	}
 
-	s := getIO()
+	switch {
+	case format.namesOnly:
+		err = writeExportEventNames(cmd, w, stream)
+	case format.countOnly:
+		err = writeExportCount(cmd, w, stream)
+	case format.csv:
+		err = writeExportCSV(w, stream, format.columns)
+	default:
+		err = writeExportEvents(cmd, w, stream, format.selected)
+	}
+	if err != nil {
+		_ = closeOutput()
+		return err
+	}
+	return closeOutput()
+}
 
+// openExportOutput returns the writer export output should go to: the file at
+// path, or stdout when path is empty, wrapped in a gzip.Writer when compress is
+// set. The returned close function flushes the gzip stream and closes the file;
+// it must be called on both success and error so compressed output is not
+// left truncated.
+func openExportOutput(path string, compress bool) (iolib.Writer, func() error, error) {
+	var w iolib.Writer = getIO().Out
+	var file *os.File
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("creating output file: %w", err)
+		}
+		file = f
+		w = f
+	}
+
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(w)
+		w = gz
+	}
+
+	closeFn := func() error {
+		var firstErr error
+		if gz != nil {
+			if err := gz.Close(); err != nil {
+				firstErr = fmt.Errorf("finishing gzip stream: %w", err)
+			}
+		}
+		if file != nil {
+			if err := file.Close(); err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("closing output file: %w", err)
+			}
+		}
+		return firstErr
+	}
+	return w, closeFn, nil
+}
+
+// writeExportEvents copies the JSONL export stream in body to w, either as
+// JSONL or, when --json is set, collected into a JSON array. When selected is
+// non-empty, each record is trimmed with selectExportProperties.
+func writeExportEvents(cmd *cobra.Command, w iolib.Writer, body iolib.Reader, selected []string) error {
 	// If --json is requested, collect all lines into a JSON array.
 	if jsonOutputRequested(cmd) {
 		var records []map[string]any
-		scanner := bufio.NewScanner(resp.Body)
+		scanner := bufio.NewScanner(body)
 		// Increase scanner buffer for large lines.
 		scanner.Buffer(make([]byte, 0, 1024*1024), 10*1024*1024)
 		for scanner.Scan() {
@@ -129,27 +292,20 @@ func runExportEvents(cmd *cobra.Command, from, to, event, where string, limit in
 			if err := json.Unmarshal(line, &record); err != nil {
 				return fmt.Errorf("parsing JSONL line: %w", err)
 			}
-			records = append(records, record)
+			records = append(records, selectExportProperties(record, selected))
 		}
 		if err := scanner.Err(); err != nil {
 			return fmt.Errorf("reading response stream: %w", err)
 		}
 
 		// Apply jq/template filters if provided.
-		var data any = records
-		handled, err := handleJSONOutput(cmd, data)
-		if err != nil {
-			return err
-		}
-		if handled {
-			return nil
-		}
-		return output.PrintJSON(s.Out, records)
+		_, err := handleJSONOutputTo(cmd, w, records)
+		return err
 	}
 
-	// Default: stream JSONL directly to stdout.
-	jw := output.NewJSONLWriter(s.Out)
-	scanner := bufio.NewScanner(resp.Body)
+	// Default: stream JSONL directly to the output.
+	jw := output.NewJSONLWriter(w)
+	scanner := bufio.NewScanner(body)
 	scanner.Buffer(make([]byte, 0, 1024*1024), 10*1024*1024)
 	for scanner.Scan() {
 		line := scanner.Bytes()
@@ -160,9 +316,198 @@ func runExportEvents(cmd *cobra.Command, from, to, event, where string, limit in
 		if err := json.Unmarshal(line, &record); err != nil {
 			return fmt.Errorf("parsing JSONL line: %w", err)
 		}
-		if err := jw.Write(record); err != nil {
+		if err := jw.Write(selectExportProperties(record, selected)); err != nil {
 			return fmt.Errorf("writing JSONL output: %w", err)
 		}
 	}
 	return scanner.Err()
 }
+
+// selectExportProperties trims an exported event to its "event" field and
+// the "time" and selected properties. It returns record unchanged when
+// selected is empty. Selected properties an event lacks are left out.
+func selectExportProperties(record map[string]any, selected []string) map[string]any {
+	if len(selected) == 0 {
+		return record
+	}
+	props, _ := record["properties"].(map[string]any)
+	kept := make(map[string]any, len(selected)+1)
+	for _, name := range append([]string{"time"}, selected...) {
+		if v, ok := props[name]; ok {
+			kept[name] = v
+		}
+	}
+	return map[string]any{"event": record["event"], "properties": kept}
+}
+
+// writeExportEventNames reads the JSONL export stream in body and writes the
+// sorted distinct event names to w, one per line or, when --json is set, as a
+// JSON array. Only the event name of each record is decoded.
+func writeExportEventNames(cmd *cobra.Command, w iolib.Writer, body iolib.Reader) error {
+	seen := make(map[string]bool)
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var record struct {
+			Event string `json:"event"`
+		}
+		if err := json.Unmarshal(line, &record); err != nil {
+			return fmt.Errorf("parsing JSONL line: %w", err)
+		}
+		seen[record.Event] = true
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading response stream: %w", err)
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	handled, err := handleJSONOutputTo(cmd, w, names)
+	if err != nil || handled {
+		return err
+	}
+	for _, name := range names {
+		if _, err := fmt.Fprintln(w, name); err != nil {
+			return fmt.Errorf("writing output: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeExportCount counts the non-empty lines of the JSONL export stream in
+// body and writes the total to w, or {"count": N} when --json is set. Records
+// are not decoded.
+func writeExportCount(cmd *cobra.Command, w iolib.Writer, body iolib.Reader) error {
+	count := 0
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 10*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) > 0 {
+			count++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading response stream: %w", err)
+	}
+
+	handled, err := handleJSONOutputTo(cmd, w, map[string]int{"count": count})
+	if err != nil || handled {
+		return err
+	}
+	if _, err := fmt.Fprintln(w, count); err != nil {
+		return fmt.Errorf("writing output: %w", err)
+	}
+	return nil
+}
+
+// writeExportCSV flattens the JSONL export stream in body into CSV rows of
+// event, time, and the given property columns. When columns is empty, every
+// record is buffered so the header can list all property names seen.
+func writeExportCSV(w iolib.Writer, body iolib.Reader, columns []string) error {
+	cw := output.NewCSVWriter(w)
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 10*1024*1024)
+
+	streaming := len(columns) > 0
+	if streaming {
+		if err := cw.WriteHeader(exportCSVHeader(columns)); err != nil {
+			return fmt.Errorf("writing CSV output: %w", err)
+		}
+	}
+
+	var buffered []map[string]any
+	propSet := make(map[string]bool)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal(line, &record); err != nil {
+			return fmt.Errorf("parsing JSONL line: %w", err)
+		}
+		if streaming {
+			if err := cw.Write(flattenExportRecord(record, columns)); err != nil {
+				return fmt.Errorf("writing CSV output: %w", err)
+			}
+			continue
+		}
+		if props, ok := record["properties"].(map[string]any); ok {
+			for k := range props {
+				if k != "time" {
+					propSet[k] = true
+				}
+			}
+		}
+		buffered = append(buffered, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading response stream: %w", err)
+	}
+
+	if !streaming {
+		for k := range propSet {
+			columns = append(columns, k)
+		}
+		sort.Strings(columns)
+		if err := cw.WriteHeader(exportCSVHeader(columns)); err != nil {
+			return fmt.Errorf("writing CSV output: %w", err)
+		}
+		for _, record := range buffered {
+			if err := cw.Write(flattenExportRecord(record, columns)); err != nil {
+				return fmt.Errorf("writing CSV output: %w", err)
+			}
+		}
+	}
+	return cw.Flush()
+}
+
+// exportCSVHeader returns the CSV header for the given property columns.
+func exportCSVHeader(columns []string) []string {
+	header := make([]string, 0, 2+len(columns))
+	header = append(header, "event", "time")
+	return append(header, columns...)
+}
+
+// flattenExportRecord maps an exported event of the form
+// {"event": "...", "properties": {"time": ..., ...}} to a CSV row of event,
+// time (formatted in UTC), and the values of the given property columns.
+func flattenExportRecord(record map[string]any, columns []string) []string {
+	props, _ := record["properties"].(map[string]any)
+
+	row := make([]string, 0, 2+len(columns))
+	row = append(row, csvCell(record["event"]), output.FormatEpoch(props["time"], time.UTC))
+	for _, col := range columns {
+		row = append(row, csvCell(props[col]))
+	}
+	return row
+}
+
+// csvCell formats a decoded JSON value for a CSV cell. Numbers are written
+// without exponent notation, and objects and arrays are encoded as JSON.
+func csvCell(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return output.FormatNumber(val)
+	case bool:
+		return strconv.FormatBool(val)
+	default:
+		b, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprintf("%v", val)
+		}
+		return string(b)
+	}
+}
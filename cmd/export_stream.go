@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	iolib "io"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// maxExportResumes is how many times a single export reconnects after the
+// stream breaks before giving up.
+const maxExportResumes = 3
+
+// exportStream is an io.Reader over the JSONL body of an export request that
+// survives mid-stream disconnects. It only ever yields whole lines. When a
+// read fails after some events were delivered, it requests the export again
+// starting the day before the last delivered event (covering project time
+// zones) and skips events up to that point.
+//
+// Skipping relies on the export returning events in time order and uses the
+// time and $insert_id of the last delivered event as the resume token. Events
+// that share that timestamp and were not yet delivered are kept, so delivery
+// is at-least-once: consumers should deduplicate on $insert_id.
+type exportStream struct {
+	// open issues the export request with the given parameters.
+	open   func(params url.Values) (iolib.ReadCloser, error)
+	params url.Values
+	from   string // the original from_date
+	limit  int    // the original limit; 0 means none
+
+	body    iolib.ReadCloser
+	r       *bufio.Reader
+	pending []byte
+	resumes int
+
+	delivered int
+	lastTime  float64
+	atLast    map[string]bool // $insert_ids delivered at lastTime
+	skipping  bool
+}
+
+// newExportStream opens the export described by params and returns a stream
+// over it.
+func newExportStream(params url.Values, open func(params url.Values) (iolib.ReadCloser, error)) (*exportStream, error) {
+	s := &exportStream{open: open, params: params, from: params.Get("from_date")}
+	if l := params.Get("limit"); l != "" {
+		s.limit, _ = strconv.Atoi(l)
+	}
+	body, err := open(params)
+	if err != nil {
+		return nil, err
+	}
+	s.setBody(body)
+	return s, nil
+}
+
+func (s *exportStream) setBody(body iolib.ReadCloser) {
+	s.body = body
+	s.r = bufio.NewReaderSize(body, 1024*1024)
+}
+
+// Close closes the current response body.
+func (s *exportStream) Close() error {
+	return s.body.Close()
+}
+
+func (s *exportStream) Read(p []byte) (int, error) {
+	for len(s.pending) == 0 {
+		line, err := s.nextLine()
+		if err != nil {
+			return 0, err
+		}
+		s.pending = append(line, '\n')
+	}
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+// nextLine returns the next event line to deliver, reconnecting if the
+// stream breaks. It returns io.EOF at the end of the export.
+func (s *exportStream) nextLine() ([]byte, error) {
+	for {
+		line, err := s.r.ReadBytes('\n')
+		if err != nil && !errors.Is(err, iolib.EOF) {
+			if resumeErr := s.resume(err); resumeErr != nil {
+				return nil, resumeErr
+			}
+			continue
+		}
+
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			if err != nil {
+				return nil, iolib.EOF
+			}
+			continue
+		}
+		if s.skip(line) {
+			continue
+		}
+		return line, nil
+	}
+}
+
+// exportEventKey is the part of an exported event the resume token needs.
+type exportEventKey struct {
+	Properties struct {
+		Time     float64 `json:"time"`
+		InsertID string  `json:"$insert_id"`
+	} `json:"properties"`
+}
+
+// skip reports whether line was already delivered before a reconnect, and
+// otherwise records it as delivered.
+func (s *exportStream) skip(line []byte) bool {
+	var key exportEventKey
+	if err := json.Unmarshal(line, &key); err != nil {
+		// Let the caller report the malformed line.
+		return false
+	}
+	t, id := key.Properties.Time, key.Properties.InsertID
+
+	if s.skipping {
+		if t < s.lastTime || (t == s.lastTime && s.atLast[id]) {
+			return true
+		}
+		s.skipping = false
+	}
+
+	if t != s.lastTime || s.atLast == nil {
+		s.lastTime = t
+		s.atLast = make(map[string]bool)
+	}
+	s.atLast[id] = true
+	s.delivered++
+	return false
+}
+
+// resume reopens the export after the read error cause, or returns an error
+// if it cannot. Exports with a limit are not resumed, since skipped events
+// would count toward the limit of the new request.
+func (s *exportStream) resume(cause error) error {
+	if s.delivered == 0 || s.limit > 0 || s.resumes >= maxExportResumes {
+		return cause
+	}
+	s.resumes++
+	_ = s.body.Close()
+
+	params := url.Values{}
+	for k, v := range s.params {
+		params[k] = v
+	}
+	from := time.Unix(int64(s.lastTime), 0).UTC().AddDate(0, 0, -1).Format(dateLayout)
+	if from < s.from {
+		from = s.from
+	}
+	params.Set("from_date", from)
+
+	getIO().Errorf("Export stream interrupted (%v); resuming from %s (attempt %d of %d)\n", cause, from, s.resumes, maxExportResumes)
+	body, err := s.open(params)
+	if err != nil {
+		return fmt.Errorf("resuming export after %v: %w", cause, err)
+	}
+	s.setBody(body)
+	s.skipping = true
+	return nil
+}
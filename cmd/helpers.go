@@ -4,88 +4,319 @@ import (
 	"encoding/json"
 	"fmt"
 	iolib "io"
+	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/aviadshiber/mp/internal/client"
 	"github.com/aviadshiber/mp/internal/output"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
 // newClient creates an authenticated Mixpanel API client from the current
 // configuration state (viper config + env vars + flags).
 func newClient() (*client.Client, error) {
+	auth, err := resolveAuth()
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := client.New(auth, resolveRegion(), resolveProjectID(), verbosity())
+	if err != nil {
+		return nil, err
+	}
+	c.SetTimeout(cfgRequestTimeout)
+	c.SetRetryJitter(viper.GetBool("retry_jitter"))
+	c.SetRateLimit(viper.GetFloat64("requests_per_second"))
+	if path := viper.GetString("ca_cert"); path != "" {
+		pemData, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA certificate: %w", err)
+		}
+		if err := c.AddRootCAs(pemData); err != nil {
+			return nil, fmt.Errorf("loading CA certificate %s: %w", path, err)
+		}
+	}
+	if viper.GetBool("insecure") {
+		c.SetInsecureSkipVerify(true)
+		warnInsecure()
+	}
+	return c, nil
+}
+
+// insecureWarning ensures the TLS verification warning is printed once per
+// run even when a command creates several clients.
+var insecureWarning sync.Once
+
+// warnInsecure prints a warning that TLS certificate verification is off. It
+// is printed even in quiet mode.
+func warnInsecure() {
+	insecureWarning.Do(func() {
+		s := getIO()
+		s.Errorf("%s TLS certificate verification is disabled (--insecure-skip-verify / MP_INSECURE); connections can be intercepted\n", s.Warning("WARNING:"))
+	})
+}
+
+// resolveAuth selects credentials based on auth_mode. When auth_mode is not
+// set, service account credentials are preferred and api_secret is used only
+// if no service account is configured. MP_TOKEN overrides the configured
+// credentials: "user:secret" for service accounts, or the bare secret in
+// api_secret mode.
+func resolveAuth() (client.Auth, error) {
+	mode := strings.ToLower(viper.GetString("auth_mode"))
 	sa := viper.GetString("service_account")
 	ss := viper.GetString("service_secret")
+	apiSecret := viper.GetString("api_secret")
+
+	if mode == "" {
+		mode = client.AuthModeServiceAccount
+		if sa == "" && ss == "" && apiSecret != "" {
+			mode = client.AuthModeAPISecret
+		}
+	}
+	if !client.ValidAuthMode(mode) {
+		return client.Auth{}, fmt.Errorf("invalid auth_mode %q; must be one of: service_account, api_secret", mode)
+	}
+
+	token := os.Getenv("MP_TOKEN")
+	if mode == client.AuthModeAPISecret {
+		if token != "" {
+			apiSecret = token
+		}
+		return client.APISecretAuth(apiSecret), nil
+	}
 
-	// MP_TOKEN env var overrides config: "user:secret".
-	if token := os.Getenv("MP_TOKEN"); token != "" {
+	if token != "" {
 		parts := strings.SplitN(token, ":", 2)
 		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
-			return nil, fmt.Errorf("MP_TOKEN must be in the format `user:secret`")
+			return client.Auth{}, fmt.Errorf("MP_TOKEN must be in the format `user:secret`")
 		}
 		sa, ss = parts[0], parts[1]
 	}
+	return client.ServiceAccountAuth(sa, ss), nil
+}
 
-	region := viper.GetString("region")
-	if region == "" {
-		region = client.RegionUS
+// resolveProjectID returns the project ID using a fixed precedence:
+// the --project-id flag, then the MP_PROJECT_ID env var, then the config file.
+// An empty flag or env value falls through to the next source.
+func resolveProjectID() string {
+	if f := rootCmd.PersistentFlags().Lookup("project-id"); f != nil && f.Changed && cfgProjectID != "" {
+		return cfgProjectID
 	}
-
-	projectID := viper.GetString("project_id")
-
-	return client.New(sa, ss, region, projectID, isDebug())
+	if pid := os.Getenv("MP_PROJECT_ID"); pid != "" {
+		return pid
+	}
+	return viper.GetString("project_id")
 }
 
 // requireProjectID returns the configured project ID or an error telling the
 // user how to set it.
 func requireProjectID() (string, error) {
-	pid := viper.GetString("project_id")
+	pid := resolveProjectID()
 	if pid == "" {
 		return "", fmt.Errorf("project ID is required; set via `--project-id`, `MP_PROJECT_ID` env, or `mp config set project_id <id>`")
 	}
 	return pid, nil
 }
 
+// defaultMaxResponseBytes caps buffered response bodies so an unexpectedly
+// large query response fails cleanly instead of exhausting memory.
+const defaultMaxResponseBytes = 256 << 20
+
 // readResponseBody reads the full body of an HTTP response and closes it.
-// It returns an error if the status code indicates a failure.
+// It returns an error if the status code indicates a failure, or if the body
+// is larger than the max_response_bytes setting (0 means unlimited).
+// Streaming commands such as `export events` do not go through it.
 func readResponseBody(resp iolib.ReadCloser, statusCode int) ([]byte, error) {
 	defer resp.Close()
-	body, err := iolib.ReadAll(resp)
+	limit := viper.GetInt64("max_response_bytes")
+	var r iolib.Reader = resp
+	if limit > 0 {
+		r = iolib.LimitReader(resp, limit+1)
+	}
+	body, err := iolib.ReadAll(r)
 	if err != nil {
 		return nil, fmt.Errorf("reading response body: %w", err)
 	}
 	if statusCode >= 400 {
-		return nil, fmt.Errorf("API error (HTTP %d): %s", statusCode, strings.TrimSpace(string(body)))
+		msg := fmt.Sprintf("API error (HTTP %d): %s", statusCode, apiErrorMessage(body))
+		if statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden {
+			msg += "\n" + regionHint()
+		}
+		return nil, &httpStatusError{status: statusCode, msg: msg}
+	}
+	if limit > 0 && int64(len(body)) > limit {
+		return nil, fmt.Errorf("response is larger than `--max-response-bytes` (%d bytes); narrow the query, use `mp export events` to stream raw data, or raise the limit (0 disables it)", limit)
 	}
 	return body, nil
 }
 
+// resolveRegion returns the configured region, lowercased, or "us" when
+// none is set.
+func resolveRegion() string {
+	region := strings.ToLower(viper.GetString("region"))
+	if region == "" {
+		region = client.RegionUS
+	}
+	return region
+}
+
+// regionHint suggests checking the region after an authentication failure,
+// since credentials for an EU or India project are rejected by the US host.
+func regionHint() string {
+	region := resolveRegion()
+	return fmt.Sprintf("Hint: verify your region (us/eu/in) matches your project's data residency (current: %s); set it with `--region` or `mp config set region <region>`", region)
+}
+
+// apiErrorMessage extracts a readable message from an error response body.
+// Mixpanel usually responds with {"error": "...", "status": 0}; when the body
+// is not JSON or has no error field, the trimmed raw body is returned.
+func apiErrorMessage(body []byte) string {
+	var parsed struct {
+		Error   any    `json:"error"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		switch e := parsed.Error.(type) {
+		case string:
+			if e != "" {
+				return e
+			}
+		case map[string]any:
+			// Some app API endpoints nest the message: {"error": {"message": "..."}}.
+			if msg, ok := e["message"].(string); ok && msg != "" {
+				return msg
+			}
+		}
+		if parsed.Message != "" {
+			return parsed.Message
+		}
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// checkAPIError reports an error embedded in a decoded response body. Some
+// Mixpanel endpoints return HTTP 200 with {"error": "...", "request_id": "..."}
+// instead of a failure status.
+func checkAPIError(result map[string]any) error {
+	msg, ok := result["error"].(string)
+	if !ok || msg == "" {
+		return nil
+	}
+	if reqID, ok := result["request_id"].(string); ok && reqID != "" {
+		return fmt.Errorf("API error: %s (request_id: %s)", msg, reqID)
+	}
+	return fmt.Errorf("API error: %s", msg)
+}
+
 // handleJSONOutput processes a parsed JSON value through --jq or --template
 // filters, or prints it as pretty JSON. It returns true if JSON output was
 // handled (i.e., --json was requested), false otherwise.
 func handleJSONOutput(cmd *cobra.Command, data any) (bool, error) {
+	return handleJSONOutputTo(cmd, getIO().Out, data)
+}
+
+// handleJSONOutputTo is like handleJSONOutput but writes to w instead of
+// standard output.
+func handleJSONOutputTo(cmd *cobra.Command, w iolib.Writer, data any) (bool, error) {
 	if !jsonOutputRequested(cmd) {
 		return false, nil
 	}
+	if err := writeJSONOutput(cmd, w, data); err != nil {
+		return true, err
+	}
+	// The table renderers report empty results through emptyResult; JSON
+	// output is checked here on the raw data so --jq and templates cannot
+	// hide an empty response.
+	if cfgFailOnEmpty && isEmptyData(data) {
+		return true, errEmptyResult
+	}
+	return true, nil
+}
 
-	s := getIO()
+// writeJSONOutput writes data as JSON, or through --jq, --template or
+// --template-preset, to w.
+func writeJSONOutput(cmd *cobra.Command, w iolib.Writer, data any) error {
+	if flatten, _ := cmd.Flags().GetBool("flatten"); flatten {
+		data = flattenOutput(data)
+	}
 
 	jqExpr, _ := cmd.Flags().GetString("jq")
 	tmpl, _ := cmd.Flags().GetString("template")
+	preset, _ := cmd.Flags().GetString("template-preset")
 
 	switch {
 	case jqExpr != "":
-		return true, output.ApplyJQ(s.Out, data, jqExpr)
+		return output.ApplyJQ(w, data, jqExpr)
 	case tmpl != "":
-		return true, output.ApplyTemplate(s.Out, data, tmpl)
+		return output.ApplyTemplate(w, data, tmpl)
+	case preset != "":
+		tmpl, err := output.TemplatePreset(preset, templatePresetDir())
+		if err != nil {
+			return err
+		}
+		return output.ApplyTemplate(w, output.NormalizeJSON(data), tmpl)
 	default:
-		return true, output.PrintJSON(s.Out, data)
+		return output.PrintJSON(w, data)
 	}
 }
 
+// templatePresetDir returns the directory of user template presets, the
+// templates directory next to the config file, or "" if it cannot be
+// determined.
+func templatePresetDir() string {
+	path, err := configFilePath()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(path), "templates")
+}
+
+// flattenOutput applies output.FlattenJSON for --flatten. Lists are flattened
+// element by element so each record keeps its own key paths.
+func flattenOutput(data any) any {
+	data = output.NormalizeJSON(data)
+	if items, ok := data.([]any); ok {
+		flat := make([]any, len(items))
+		for i, item := range items {
+			flat[i] = output.FlattenJSON(item)
+		}
+		return flat
+	}
+	if data == nil {
+		return nil
+	}
+	return output.FlattenJSON(data)
+}
+
+// reportRetries prints a muted one-line summary to stderr when c had to retry
+// any requests. It is meant for long-running commands and never writes to
+// stdout, so --json output is unaffected.
+func reportRetries(c *client.Client) {
+	stats := c.RetryStats()
+	s := getIO()
+	if stats.Retries == 0 || s.IsQuiet() {
+		return
+	}
+	s.Errorf("%s\n", s.Muted(fmt.Sprintf("Retried %s (%d rate limited, %d server errors)",
+		pluralize(stats.Retries, "request"), stats.RateLimited, stats.ServerErrors)))
+}
+
+// pluralize returns "1 cohort" or "N cohorts" for footers and messages.
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
 // toJSONArray encodes a string slice as a JSON array string,
 // e.g., ["Signup","Login"].
 func toJSONArray(items []string) string {
@@ -118,3 +349,311 @@ func splitCSV(s string) []string {
 	}
 	return result
 }
+
+// dateLayout is the yyyy-mm-dd format Mixpanel expects for date ranges.
+const dateLayout = "2006-01-02"
+
+// dateFlagAliases maps alternative date range flag names to their canonical
+// names, e.g. --since to --from.
+var dateFlagAliases = map[string]string{
+	"since": "from",
+	"until": "to",
+}
+
+// addDateRangeFlags registers --from and --to on cmd, accepting --since and
+// --until as aliases. Mixpanel treats both ends of the range as inclusive, so
+// --from 2024-01-01 --to 2024-01-01 covers one full day. The dates are checked
+// with validateDateRange before the command runs.
+func addDateRangeFlags(cmd *cobra.Command, from, to *string, required bool) {
+	suffix := ", inclusive"
+	if required {
+		suffix += ", required"
+	}
+	cmd.Flags().StringVar(from, "from", "", "Start date yyyy-mm-dd"+suffix+" (alias: --since)")
+	cmd.Flags().StringVar(to, "to", "", "End date yyyy-mm-dd"+suffix+" (alias: --until)")
+	cmd.Flags().SetNormalizeFunc(func(_ *pflag.FlagSet, name string) pflag.NormalizedName {
+		if canonical, ok := dateFlagAliases[name]; ok {
+			name = canonical
+		}
+		return pflag.NormalizedName(name)
+	})
+	if required {
+		_ = cmd.MarkFlagRequired("from")
+		_ = cmd.MarkFlagRequired("to")
+	}
+
+	chainPreRunE(cmd, func() error {
+		return validateDateRange(*from, *to)
+	})
+}
+
+// metadataRequestTimeout is the request timeout of commands that list or
+// inspect project metadata, which should answer quickly or fail fast.
+const metadataRequestTimeout = 30 * time.Second
+
+// timeoutAnnotation is the cobra annotation holding a command's default
+// request timeout.
+const timeoutAnnotation = "mp_request_timeout"
+
+// setRequestTimeout sets the default request timeout of cmd and its
+// subcommands; 0 means no timeout. --timeout overrides it.
+func setRequestTimeout(cmd *cobra.Command, d time.Duration) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[timeoutAnnotation] = d.String()
+}
+
+// requestTimeout returns the request timeout for cmd: --timeout or
+// MP_TIMEOUT when set, otherwise the default of cmd or its nearest ancestor
+// that sets one, otherwise client.DefaultTimeout.
+func requestTimeout(cmd *cobra.Command) (time.Duration, error) {
+	if viper.IsSet("timeout") {
+		d, err := time.ParseDuration(viper.GetString("timeout"))
+		if err != nil || d < 0 {
+			return 0, fmt.Errorf("invalid timeout %q; must be a non-negative duration such as 30s or 5m", viper.GetString("timeout"))
+		}
+		return d, nil
+	}
+	for c := cmd; c != nil; c = c.Parent() {
+		if v, ok := c.Annotations[timeoutAnnotation]; ok {
+			return time.ParseDuration(v)
+		}
+	}
+	return client.DefaultTimeout, nil
+}
+
+// chainPreRunE makes cmd run fn after its existing PreRunE, if any, so flag
+// helpers can each add a check without replacing one another's. Checks run in
+// the order they were added.
+func chainPreRunE(cmd *cobra.Command, fn func() error) {
+	prev := cmd.PreRunE
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if prev != nil {
+			if err := prev(cmd, args); err != nil {
+				return err
+			}
+		}
+		return fn()
+	}
+}
+
+// queryFlags holds the values of the flags shared by the query commands.
+type queryFlags struct {
+	from  string
+	to    string
+	unit  string
+	on    string
+	where string
+	limit int
+}
+
+// queryFlagSpec describes how a query command's endpoint takes the common
+// query flags. A flag whose field is empty is not registered.
+type queryFlagSpec struct {
+	units        []string // values --unit accepts
+	unitRequired bool
+	on           string // help text for --on
+	where        string // help text for --where
+	limit        string // what --limit counts, e.g. "breakdown values"
+	maxLimit     int    // documented maximum of --limit; 0 if none
+}
+
+// Documented maxima of --limit for the endpoints that enforce one. The
+// commands check them in RunE so an oversized limit fails locally instead of
+// with an opaque API error.
+const (
+	maxSegmentationLimit = 10000
+	maxPropertiesLimit   = 10000
+	maxFunnelsLimit      = 10000
+)
+
+// validateLimit checks a --limit value against an endpoint's maximum.
+// Negative values are rejected earlier, by addCommonQueryFlags.
+func validateLimit(limit, max int) error {
+	if limit > max {
+		return fmt.Errorf("`--limit` %d exceeds the maximum of %d for this query", limit, max)
+	}
+	return nil
+}
+
+// Help text for the common query flags that most commands share.
+const (
+	breakdownHelp = "Property expression for breakdown (e.g., properties[\"country\"])"
+	filterHelp    = "Filter expression"
+)
+
+// addCommonQueryFlags registers the required --from/--to range and the flags
+// of spec on cmd, and returns the values they are bound to. --unit is checked
+// against spec.units and --limit against negative values before the command
+// runs; commands still mark their other flags required themselves.
+func addCommonQueryFlags(cmd *cobra.Command, spec queryFlagSpec) *queryFlags {
+	q := &queryFlags{}
+	addDateRangeFlags(cmd, &q.from, &q.to, true)
+
+	if len(spec.units) > 0 {
+		help := "Time unit: " + strings.Join(spec.units, ", ")
+		if spec.unitRequired {
+			help += " (required)"
+		}
+		cmd.Flags().StringVar(&q.unit, "unit", "", help)
+		if spec.unitRequired {
+			_ = cmd.MarkFlagRequired("unit")
+		}
+	}
+	if spec.on != "" {
+		cmd.Flags().StringVar(&q.on, "on", "", spec.on)
+	}
+	if spec.where != "" {
+		cmd.Flags().StringVar(&q.where, "where", "", spec.where)
+	}
+	if spec.limit != "" {
+		help := "Maximum number of " + spec.limit
+		if spec.maxLimit > 0 {
+			help += fmt.Sprintf(" (max %d)", spec.maxLimit)
+		}
+		cmd.Flags().IntVar(&q.limit, "limit", 0, help)
+	}
+
+	chainPreRunE(cmd, func() error {
+		if q.unit != "" && !slices.Contains(spec.units, q.unit) {
+			return fmt.Errorf("invalid `--unit` %q; must be one of: %s", q.unit, strings.Join(spec.units, ", "))
+		}
+		if q.limit < 0 {
+			return fmt.Errorf("`--limit` must not be negative")
+		}
+		return nil
+	})
+	return q
+}
+
+// validateDateRange checks that from and to, when set, are yyyy-mm-dd dates
+// and that from is not after to.
+func validateDateRange(from, to string) error {
+	return validateDateFlags("from", from, "to", to)
+}
+
+// validateDateFlags is validateDateRange for a range given by the flags named
+// fromFlag and toFlag, which are used in error messages.
+func validateDateFlags(fromFlag, from, toFlag, to string) error {
+	var start, end time.Time
+	var err error
+	if from != "" {
+		if start, err = time.Parse(dateLayout, from); err != nil {
+			return fmt.Errorf("invalid `--%s` date %q: expected yyyy-mm-dd", fromFlag, from)
+		}
+	}
+	if to != "" {
+		if end, err = time.Parse(dateLayout, to); err != nil {
+			return fmt.Errorf("invalid `--%s` date %q: expected yyyy-mm-dd", toFlag, to)
+		}
+	}
+	if from != "" && to != "" && start.After(end) {
+		return fmt.Errorf("`--%s` %s is after `--%s` %s; both ends are inclusive, so use the same date for a single day", fromFlag, from, toFlag, to)
+	}
+	return nil
+}
+
+// resolveDistinctIDs returns the distinct IDs given inline as a comma-separated
+// list or in the file at path, which is read with readDistinctIDsFile. At most
+// one of the two may be set. Duplicates are removed, keeping the first
+// occurrence.
+func resolveDistinctIDs(inline, path string) ([]string, error) {
+	if inline != "" && path != "" {
+		return nil, fmt.Errorf("`--distinct-ids` and `--distinct-ids-file` cannot be used together")
+	}
+	ids := splitCSV(inline)
+	if path != "" {
+		var err error
+		if ids, err = readDistinctIDsFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	seen := make(map[string]bool, len(ids))
+	unique := ids[:0]
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique, nil
+}
+
+// readDistinctIDsFile reads distinct IDs from path, or from stdin when path is
+// "-". The content is either a JSON array or one ID per line; blank lines are
+// ignored.
+func readDistinctIDsFile(path string) ([]string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = iolib.ReadAll(getIO().In)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading distinct IDs: %w", err)
+	}
+
+	content := strings.TrimSpace(string(data))
+	if strings.HasPrefix(content, "[") {
+		var raw []any
+		if err := json.Unmarshal([]byte(content), &raw); err != nil {
+			return nil, fmt.Errorf("parsing distinct IDs file as a JSON array: %w", err)
+		}
+		ids := make([]string, 0, len(raw))
+		for _, v := range raw {
+			if id := csvCell(v); id != "" {
+				ids = append(ids, id)
+			}
+		}
+		return ids, nil
+	}
+
+	var ids []string
+	for _, line := range strings.Split(content, "\n") {
+		if id := strings.TrimSpace(line); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// chunkStrings splits items into consecutive batches of at most size items.
+func chunkStrings(items []string, size int) [][]string {
+	var chunks [][]string
+	for len(items) > size {
+		chunks = append(chunks, items[:size])
+		items = items[size:]
+	}
+	if len(items) > 0 {
+		chunks = append(chunks, items)
+	}
+	return chunks
+}
+
+// forEachConcurrent calls fn for i in [0, n) using at most the configured
+// --concurrency goroutines at a time. It waits for all calls to finish and
+// returns the error of the lowest index that failed.
+func forEachConcurrent(n int, fn func(i int) error) error {
+	errs := make([]error, n)
+	sem := make(chan struct{}, viper.GetInt("concurrency"))
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = fn(i)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	iolib "io"
+	"net/url"
+	"os"
+
+	"github.com/aviadshiber/mp/internal/client"
+	"github.com/spf13/cobra"
+)
+
+// importBatchSize is the maximum number of events the /import endpoint
+// accepts per request.
+const importBatchSize = 2000
+
+func init() {
+	rootCmd.AddCommand(newImportCmd())
+}
+
+func newImportCmd() *cobra.Command {
+	importCmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import data into Mixpanel",
+		Long:  "Import historical event data into your Mixpanel project.",
+	}
+
+	importCmd.AddCommand(newImportEventsCmd())
+	return importCmd
+}
+
+func newImportEventsCmd() *cobra.Command {
+	var (
+		file    string
+		onError string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: "Import events from a JSONL file",
+		Long: `Import events from a JSONL file (one event per line) using the ingestion
+/import endpoint. Each record must look like:
+
+  {"event": "Signup", "properties": {"time": 1704067200, "distinct_id": "u1", "$insert_id": "..."}}
+
+Records are validated locally before sending: each must have a non-empty
+"event" name, a numeric "properties.time", and a non-empty
+"properties.$insert_id". Use --on-error to skip invalid records or abort on the
+first one (the default). Invalid records are reported with their line number.`,
+		Example: `  # Import events from a file
+  mp import events --file events.jsonl
+
+  # Skip invalid records instead of aborting
+  mp import events --file events.jsonl --on-error skip
+
+  # Read from stdin
+  cat events.jsonl | mp import events --file -`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runImportEvents(cmd, file, onError)
+		},
+	}
+
+	cmd.Flags().StringVar(&file, "file", "", "JSONL file to import, or - for stdin (required)")
+	cmd.Flags().StringVar(&onError, "on-error", "abort", "Action on invalid records: skip, abort")
+
+	_ = cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+func runImportEvents(cmd *cobra.Command, file, onError string) error {
+	if onError != "skip" && onError != "abort" {
+		return fmt.Errorf("invalid `--on-error` %q; must be one of: skip, abort", onError)
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	if err := addProjectID(params); err != nil {
+		return err
+	}
+	params.Set("strict", "1")
+
+	s := getIO()
+
+	var in iolib.Reader = s.In
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return fmt.Errorf("opening import file: %w", err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	imported, skipped := 0, 0
+	batch := make([]map[string]any, 0, importBatchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		resp, err := c.PostJSON(client.APIFamilyIngestion, "/import", params, batch)
+		if err != nil {
+			return fmt.Errorf("importing events: %w", err)
+		}
+		if _, err := readResponseBody(resp.Body, resp.StatusCode); err != nil {
+			return err
+		}
+		imported += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 1024*1024), 10*1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var record map[string]any
+		err := json.Unmarshal(line, &record)
+		if err == nil {
+			err = validateEventRecord(record)
+		}
+		if err != nil {
+			if onError == "abort" {
+				return fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			s.Errorf("%s line %d: %v\n", s.Warning("skipping"), lineNum, err)
+			skipped++
+			continue
+		}
+
+		batch = append(batch, record)
+		if len(batch) == importBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading import file: %w", err)
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	summary := map[string]any{
+		"imported": imported,
+		"skipped":  skipped,
+	}
+	handled, err := handleJSONOutput(cmd, summary)
+	if err != nil {
+		return err
+	}
+	if handled {
+		return nil
+	}
+
+	s.Printf("%s Imported %d events", s.Success(""), imported)
+	if skipped > 0 {
+		s.Printf(" (%d invalid records skipped)", skipped)
+	}
+	s.Printf("\n")
+	return nil
+}
+
+// validateEventRecord checks that a decoded event has the fields the /import
+// endpoint requires: a non-empty "event" name, a numeric "properties.time",
+// and a non-empty "properties.$insert_id".
+func validateEventRecord(record map[string]any) error {
+	if name, _ := record["event"].(string); name == "" {
+		return fmt.Errorf("missing or empty \"event\" name")
+	}
+	props, ok := record["properties"].(map[string]any)
+	if !ok {
+		return fmt.Errorf("missing \"properties\" object")
+	}
+	if _, ok := props["time"].(float64); !ok {
+		return fmt.Errorf("\"properties.time\" must be a numeric timestamp")
+	}
+	if id, _ := props["$insert_id"].(string); id == "" {
+		return fmt.Errorf("missing or empty \"properties.$insert_id\"")
+	}
+	return nil
+}
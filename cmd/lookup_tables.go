@@ -23,6 +23,8 @@ func newLookupTablesCmd() *cobra.Command {
 		Long:    "List and inspect lookup tables in your Mixpanel project.",
 	}
 
+	setRequestTimeout(lookupTablesCmd, metadataRequestTimeout)
+
 	lookupTablesCmd.AddCommand(newLookupTablesListCmd())
 	return lookupTablesCmd
 }
@@ -39,7 +41,7 @@ func newLookupTablesListCmd() *cobra.Command {
   mp lookup-tables list --json
 
   # Filter with jq
-  mp lookup-tables list --json --jq '.[].name'`,
+  mp lookup-tables list --jq '.[].name'`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runLookupTablesList(cmd)
 		},
@@ -117,8 +119,7 @@ func renderLookupTables(result any) error {
 	}
 
 	if len(tables) == 0 {
-		s.Printf("No lookup tables found.\n")
-		return nil
+		return emptyResult(s, "No lookup tables found.")
 	}
 
 	// Sort by name for consistent output.
@@ -158,6 +159,7 @@ func renderLookupTables(result any) error {
 	}
 
 	output.PrintTable(s.Out, headers, rows, s.IsTerminal())
+	s.Footer("%s", pluralize(len(rows), "lookup table"))
 	return nil
 }
 
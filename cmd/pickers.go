@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/aviadshiber/mp/internal/client"
+	"github.com/aviadshiber/mp/internal/prompt"
+	"github.com/spf13/cobra"
+)
+
+// eventNamesLimit is how many of the project's top events the event picker
+// offers.
+const eventNamesLimit = 255
+
+// addFunnelPicker makes --funnel-id required unless ad hoc steps are given,
+// except that in an interactive terminal a missing ID is chosen from the
+// project's saved funnels.
+func addFunnelPicker(cmd *cobra.Command, funnelID *int, steps *[]string) {
+	chainPreRunE(cmd, func() error {
+		if *funnelID != 0 || len(*steps) > 0 {
+			return nil
+		}
+		if !getIO().CanPrompt() {
+			return fmt.Errorf("one of `--funnel-id` or `--steps` is required")
+		}
+		id, err := pickFunnelID()
+		if err != nil {
+			return err
+		}
+		*funnelID = id
+		return nil
+	})
+}
+
+// addEventPicker makes --event required, except that in an interactive
+// terminal a missing event is chosen from the project's top events.
+func addEventPicker(cmd *cobra.Command, event *string) {
+	chainPreRunE(cmd, func() error {
+		if *event != "" {
+			return nil
+		}
+		if !getIO().CanPrompt() {
+			return missingFlagError("event")
+		}
+		name, err := pickEventName()
+		if err != nil {
+			return err
+		}
+		*event = name
+		return nil
+	})
+}
+
+// missingFlagError matches cobra's error for an unset required flag, so
+// non-interactive use behaves as if the flag were marked required.
+func missingFlagError(name string) error {
+	return fmt.Errorf("required flag(s) %q not set", name)
+}
+
+func pickFunnelID() (int, error) {
+	funnels, err := fetchFunnels()
+	if err != nil {
+		return 0, err
+	}
+	sort.Slice(funnels, func(i, j int) bool {
+		idI, _ := funnels[i]["funnel_id"].(float64)
+		idJ, _ := funnels[j]["funnel_id"].(float64)
+		return idI < idJ
+	})
+
+	options := make([]prompt.Option, 0, len(funnels))
+	for _, f := range funnels {
+		id := fmt.Sprintf("%.0f", f["funnel_id"])
+		name, _ := f["name"].(string)
+		options = append(options, prompt.Option{Label: fmt.Sprintf("%s (%s)", name, id), Value: id})
+	}
+	if len(options) == 0 {
+		return 0, fmt.Errorf("no saved funnels found; pass `--funnel-id`")
+	}
+
+	s := getIO()
+	choice, err := prompt.Select(s.In, s.ErrOut, "Select a funnel", options)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(choice.Value)
+}
+
+func pickEventName() (string, error) {
+	names, err := fetchEventNames()
+	if err != nil {
+		return "", err
+	}
+	if len(names) == 0 {
+		return "", fmt.Errorf("no events found; pass `--event`")
+	}
+
+	options := make([]prompt.Option, len(names))
+	for i, name := range names {
+		options[i] = prompt.Option{Label: name, Value: name}
+	}
+
+	s := getIO()
+	choice, err := prompt.Select(s.In, s.ErrOut, "Select an event", options)
+	if err != nil {
+		return "", err
+	}
+	return choice.Value, nil
+}
+
+// fetchEventNames returns the project's most common event names from the
+// last 31 days.
+// Response shape: ["Signup", "Login", ...]
+func fetchEventNames() ([]string, error) {
+	c, err := newClient()
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	if err := addProjectID(params); err != nil {
+		return nil, err
+	}
+	params.Set("type", "general")
+	params.Set("limit", strconv.Itoa(eventNamesLimit))
+
+	body, err := cachedResponse("/events/names", params, func(h http.Header) (*http.Response, error) {
+		resp, err := c.GetWithHeader(client.APIFamilyQuery, "/events/names", params, h)
+		if err != nil {
+			return nil, fmt.Errorf("listing event names: %w", err)
+		}
+		return resp, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	if err := json.Unmarshal(body, &names); err != nil {
+		return nil, fmt.Errorf("parsing event names response: %w", err)
+	}
+	return names, nil
+}
@@ -22,6 +22,8 @@ func newPipelinesCmd() *cobra.Command {
 		Long:  "List and inspect Mixpanel data pipeline jobs and their status.",
 	}
 
+	setRequestTimeout(pipelinesCmd, metadataRequestTimeout)
+
 	pipelinesCmd.AddCommand(newPipelinesListCmd())
 	pipelinesCmd.AddCommand(newPipelinesStatusCmd())
 	return pipelinesCmd
@@ -39,7 +41,7 @@ func newPipelinesListCmd() *cobra.Command {
   mp pipelines list --json
 
   # Filter with jq
-  mp pipelines list --json --jq '.. | .name? // empty'`,
+  mp pipelines list --jq '.. | .name? // empty'`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runPipelinesList(cmd)
 		},
@@ -137,8 +139,7 @@ func renderPipelinesList(result any) error {
 	}
 
 	if len(jobs) == 0 {
-		s.Printf("No pipeline jobs found.\n")
-		return nil
+		return emptyResult(s, "No pipeline jobs found.")
 	}
 
 	// Sort by name for consistent output.
@@ -154,6 +155,7 @@ func renderPipelinesList(result any) error {
 	}
 
 	output.PrintTable(s.Out, headers, rows, s.IsTerminal())
+	s.Footer("%s", pluralize(len(rows), "pipeline job"))
 	return nil
 }
 
@@ -10,8 +10,12 @@ import (
 	"github.com/aviadshiber/mp/internal/client"
 	"github.com/aviadshiber/mp/internal/output"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
+// maxEngagePageSize is the largest page the Engage API accepts.
+const maxEngagePageSize = 1000
+
 func init() {
 	rootCmd.AddCommand(newProfilesCmd())
 }
@@ -23,8 +27,14 @@ func newProfilesCmd() *cobra.Command {
 		Long:  "Query, inspect, and manage Mixpanel user profiles (Engage API).",
 	}
 
+	profilesCmd.PersistentFlags().Bool("clamp-page-size", false, "Clamp an out-of-range --page-size to 1-1000 with a warning instead of failing")
+	_ = viper.BindPFlag("clamp_page_size", profilesCmd.PersistentFlags().Lookup("clamp-page-size"))
+
+	setRequestTimeout(profilesCmd, 0)
+
 	profilesCmd.AddCommand(newProfilesQueryCmd())
 	profilesCmd.AddCommand(newProfilesGroupsCmd())
+	profilesCmd.AddCommand(newProfilesCountCmd())
 	return profilesCmd
 }
 
@@ -33,10 +43,12 @@ func newProfilesQueryCmd() *cobra.Command {
 		where       string
 		distinctID  string
 		distinctIDs string
+		idsFile     string
 		properties  string
 		cohortID    int
 		limit       int
 		pageSize    int
+		dedup       bool
 	)
 
 	cmd := &cobra.Command{
@@ -46,7 +58,16 @@ func newProfilesQueryCmd() *cobra.Command {
 through all matching results unless a --limit is specified.
 
 Results are returned as a table by default showing distinct_id and selected
-properties. Use --json for the full API response.`,
+properties. Use --json for the full API response.
+
+If profiles are being written while you paginate, Engage can return the same
+profile on adjacent pages. --dedup skips repeated distinct IDs on a
+best-effort basis and adjusts "count" accordingly.
+
+For long lists of users, --distinct-ids-file reads IDs from a file (or stdin
+with "-"), one per line or as a JSON array. Duplicate IDs are dropped, and the
+IDs are sent in batches of 1000 whose results are merged; "total" is then the
+sum over all batches.`,
 		Example: `  # Find a user by email
   mp profiles query --where 'user["$email"]=="alice@example.com"'
 
@@ -62,20 +83,32 @@ properties. Use --json for the full API response.`,
   # Multiple distinct IDs
   mp profiles query --distinct-ids "user1,user2,user3"
 
+  # Look up thousands of users listed in a file
+  mp profiles query --distinct-ids-file users.txt --properties '$email'
+
+  # Skip profiles repeated across pages
+  mp profiles query --where 'defined(user["$email"])' --dedup
+
   # JSON output
   mp profiles query --where 'user["$city"]=="San Francisco"' --json`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runProfilesQuery(cmd, where, distinctID, distinctIDs, properties, cohortID, limit, pageSize)
+			ids, err := resolveDistinctIDs(distinctIDs, idsFile)
+			if err != nil {
+				return err
+			}
+			return runProfilesQuery(cmd, where, distinctID, ids, properties, cohortID, limit, pageSize, dedup)
 		},
 	}
 
 	cmd.Flags().StringVar(&where, "where", "", "Filter expression (e.g., user[\"$email\"]==\"alice@example.com\")")
 	cmd.Flags().StringVar(&distinctID, "distinct-id", "", "Single distinct ID to look up")
 	cmd.Flags().StringVar(&distinctIDs, "distinct-ids", "", "Comma-separated list of distinct IDs")
+	cmd.Flags().StringVar(&idsFile, "distinct-ids-file", "", "File of distinct IDs, one per line or a JSON array; - reads stdin")
 	cmd.Flags().StringVar(&properties, "properties", "", "Comma-separated output property names (e.g., $email,$name)")
 	cmd.Flags().IntVar(&cohortID, "cohort-id", 0, "Filter by cohort ID")
 	cmd.Flags().IntVar(&limit, "limit", 0, "Maximum total profiles to fetch (0 = all)")
 	cmd.Flags().IntVar(&pageSize, "page-size", 1000, "Profiles per page (max 1000)")
+	cmd.Flags().BoolVar(&dedup, "dedup", false, "Skip profiles whose distinct_id was already returned on an earlier page (best-effort)")
 
 	return cmd
 }
@@ -88,17 +121,56 @@ type engageResponse struct {
 	Status    string           `json:"status"`
 	Total     int              `json:"total"`
 	Results   []map[string]any `json:"results"`
+	Error     string           `json:"error"`
+	Request   string           `json:"request"`
 }
 
-func runProfilesQuery(cmd *cobra.Command, where, distinctID, distinctIDs, properties string, cohortID, limit, pageSize int) error {
-	if pageSize < 1 || pageSize > 1000 {
-		return fmt.Errorf("`--page-size` must be between 1 and 1000")
+// apiError returns an error if the page reports a failure, either through
+// the "error" field (which Mixpanel may send with HTTP 200) or a non-ok status.
+func (r engageResponse) apiError() error {
+	if r.Error != "" {
+		if r.Request != "" {
+			return fmt.Errorf("engage API error: %s (request: %s)", r.Error, r.Request)
+		}
+		return fmt.Errorf("engage API error: %s", r.Error)
+	}
+	if r.Status != "ok" && r.Status != "" {
+		return fmt.Errorf("engage API returned status %q", r.Status)
+	}
+	return nil
+}
+
+// resolvePageSize validates an Engage --page-size. Out-of-range values are an
+// error unless clamp_page_size is enabled, in which case they are clamped to
+// 1-1000 and a warning is printed.
+func resolvePageSize(pageSize int) (int, error) {
+	if pageSize >= 1 && pageSize <= maxEngagePageSize {
+		return pageSize, nil
+	}
+	if !viper.GetBool("clamp_page_size") {
+		return 0, fmt.Errorf("`--page-size` must be between 1 and %d", maxEngagePageSize)
+	}
+	clamped := min(max(pageSize, 1), maxEngagePageSize)
+	s := getIO()
+	s.Errorf("%s `--page-size` %d is out of range; using %d\n", s.Warning("warning:"), pageSize, clamped)
+	return clamped, nil
+}
+
+// engageDistinctIDsBatch is how many distinct IDs mp sends per Engage query.
+// Longer lists are split into several queries whose results are merged.
+const engageDistinctIDsBatch = 1000
+
+func runProfilesQuery(cmd *cobra.Command, where, distinctID string, distinctIDs []string, properties string, cohortID, limit, pageSize int, dedup bool) error {
+	pageSize, err := resolvePageSize(pageSize)
+	if err != nil {
+		return err
 	}
 
 	c, err := newClient()
 	if err != nil {
 		return err
 	}
+	defer reportRetries(c)
 
 	// Build base form parameters.
 	baseParams := url.Values{}
@@ -112,10 +184,6 @@ func runProfilesQuery(cmd *cobra.Command, where, distinctID, distinctIDs, proper
 	if distinctID != "" {
 		baseParams.Set("distinct_id", distinctID)
 	}
-	if distinctIDs != "" {
-		ids := splitCSV(distinctIDs)
-		baseParams.Set("distinct_ids", toJSONArray(ids))
-	}
 	if properties != "" {
 		props := splitCSV(properties)
 		baseParams.Set("output_properties", toJSONArray(props))
@@ -126,10 +194,76 @@ func runProfilesQuery(cmd *cobra.Command, where, distinctID, distinctIDs, proper
 	}
 	baseParams.Set("page_size", strconv.Itoa(pageSize))
 
-	// Auto-paginate.
+	var seen map[string]bool
+	if dedup {
+		seen = make(map[string]bool)
+	}
+
+	// Without distinct IDs there is a single query; otherwise one per batch.
+	batches := [][]string{nil}
+	if len(distinctIDs) > 0 {
+		batches = chunkStrings(distinctIDs, engageDistinctIDsBatch)
+	}
+
+	// Auto-paginate each query.
 	var allResults []map[string]any
+	totalFromAPI := 0
+	for _, batch := range batches {
+		params := baseParams
+		if batch != nil {
+			params = url.Values{}
+			for k, v := range baseParams {
+				params[k] = v
+			}
+			params.Set("distinct_ids", toJSONArray(batch))
+		}
+
+		limitReached := false
+		total, err := engagePages(c, params, pageSize, "profiles", func(page []map[string]any) (bool, error) {
+			allResults = appendUniqueProfiles(allResults, page, seen)
+			if limit > 0 && len(allResults) >= limit {
+				allResults = allResults[:limit]
+				limitReached = true
+				return false, nil
+			}
+			return true, nil
+		})
+		if err != nil {
+			return err
+		}
+		totalFromAPI += total
+		if limitReached {
+			break
+		}
+	}
+
+	// Build a combined response for JSON output.
+	combined := map[string]any{
+		"total":   totalFromAPI,
+		"count":   len(allResults),
+		"results": allResults,
+	}
+
+	handled, err := handleJSONOutput(cmd, combined)
+	if err != nil {
+		return err
+	}
+	if handled {
+		return nil
+	}
+
+	// Default: render table.
+	return renderProfilesTable(allResults, properties)
+}
+
+// engagePages paginates the Engage API for baseParams, calling fn with the
+// results of each page in order. It stops after the last page, or early when
+// fn returns false or an error. label names the query in error messages. It
+// returns the total reported by the first page.
+func engagePages(c *client.Client, baseParams url.Values, pageSize int, label string, fn func(page []map[string]any) (bool, error)) (int, error) {
 	var sessionID string
 	page := 0
+	fetched := 0
 	totalFromAPI := -1
 
 	for {
@@ -144,61 +278,60 @@ func runProfilesQuery(cmd *cobra.Command, where, distinctID, distinctIDs, proper
 
 		resp, err := c.Post(client.APIFamilyQuery, "/engage", params)
 		if err != nil {
-			return fmt.Errorf("querying profiles (page %d): %w", page, err)
+			return 0, fmt.Errorf("querying %s (page %d): %w", label, page, err)
 		}
 
 		body, err := readResponseBody(resp.Body, resp.StatusCode)
 		if err != nil {
-			return err
+			return 0, err
 		}
 
 		var pageResp engageResponse
 		if err := json.Unmarshal(body, &pageResp); err != nil {
-			return fmt.Errorf("parsing profiles response: %w", err)
+			return 0, fmt.Errorf("parsing %s response: %w", label, err)
 		}
 
-		if pageResp.Status != "ok" && pageResp.Status != "" {
-			return fmt.Errorf("engage API returned status %q", pageResp.Status)
+		if err := pageResp.apiError(); err != nil {
+			return 0, err
 		}
 
-		allResults = append(allResults, pageResp.Results...)
 		sessionID = pageResp.SessionID
+		fetched += len(pageResp.Results)
 		if totalFromAPI < 0 {
 			totalFromAPI = pageResp.Total
 		}
 
-		// Check if we have enough results or reached the end.
-		if limit > 0 && len(allResults) >= limit {
-			allResults = allResults[:limit]
-			break
-		}
-		if len(allResults) >= totalFromAPI {
-			break
+		more, err := fn(pageResp.Results)
+		if err != nil {
+			return 0, err
 		}
-		if len(pageResp.Results) < pageSize {
-			break
+
+		// Stop when the caller has enough or the last page was reached.
+		if !more || fetched >= totalFromAPI || len(pageResp.Results) < pageSize {
+			return totalFromAPI, nil
 		}
 
 		page++
 	}
+}
 
-	// Build a combined response for JSON output.
-	combined := map[string]any{
-		"total":   totalFromAPI,
-		"count":   len(allResults),
-		"results": allResults,
-	}
-
-	handled, err := handleJSONOutput(cmd, combined)
-	if err != nil {
-		return err
+// appendUniqueProfiles appends page to results. When seen is non-nil, profiles
+// whose $distinct_id is already in seen are skipped, which filters duplicates
+// that Engage can return on adjacent pages while profiles are being written.
+// Dedup is best-effort: it only catches repeats within a single run.
+func appendUniqueProfiles(results, page []map[string]any, seen map[string]bool) []map[string]any {
+	if seen == nil {
+		return append(results, page...)
 	}
-	if handled {
-		return nil
+	for _, profile := range page {
+		id := fmt.Sprint(profile["$distinct_id"])
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		results = append(results, profile)
 	}
-
-	// Default: render table.
-	return renderProfilesTable(allResults, properties)
+	return results
 }
 
 // renderProfilesTable renders profile results as a table with distinct_id
@@ -207,8 +340,7 @@ func renderProfilesTable(results []map[string]any, propertiesFlag string) error
 	s := getIO()
 
 	if len(results) == 0 {
-		s.Printf("No profiles found.\n")
-		return nil
+		return emptyResult(s, "No profiles found.")
 	}
 
 	// Determine which property columns to show.
@@ -263,6 +395,6 @@ func renderProfilesTable(results []map[string]any, propertiesFlag string) error
 	}
 
 	output.PrintTable(s.Out, headers, rows, s.IsTerminal())
-	s.Printf("\n%s %d profiles\n", s.Muted("Showing"), len(results))
+	s.Footer("Showing %s", pluralize(len(results), "profile"))
 	return nil
 }
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/aviadshiber/mp/internal/client"
+	"github.com/spf13/cobra"
+)
+
+func newProfilesCountCmd() *cobra.Command {
+	var (
+		where    string
+		cohortID int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "count",
+		Short: "Count user profiles matching a filter",
+		Long: `Count user profiles matching a filter without fetching them. Issues a single
+Engage request for the first page and prints the "total" it reports.`,
+		Example: `  # Count all profiles
+  mp profiles count
+
+  # Count profiles in San Francisco
+  mp profiles count --where 'user["$city"]=="San Francisco"'
+
+  # Count profiles in a cohort
+  mp profiles count --cohort-id 67890
+
+  # JSON output
+  mp profiles count --where 'defined(user["$email"])' --json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runProfilesCount(cmd, where, cohortID)
+		},
+	}
+
+	cmd.Flags().StringVar(&where, "where", "", "Filter expression (e.g., user[\"$city\"]==\"San Francisco\")")
+	cmd.Flags().IntVar(&cohortID, "cohort-id", 0, "Filter by cohort ID")
+
+	return cmd
+}
+
+func runProfilesCount(cmd *cobra.Command, where string, cohortID int) error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	if err := addProjectID(params); err != nil {
+		return err
+	}
+	if where != "" {
+		params.Set("where", where)
+	}
+	if cohortID > 0 {
+		cohortJSON, _ := json.Marshal(map[string]int{"id": cohortID})
+		params.Set("filter_by_cohort", string(cohortJSON))
+	}
+	// Only the total is needed, so keep the page as small as possible.
+	params.Set("page", "0")
+	params.Set("page_size", "1")
+	params.Set("output_properties", toJSONArray([]string{"$distinct_id"}))
+
+	resp, err := c.Post(client.APIFamilyQuery, "/engage", params)
+	if err != nil {
+		return fmt.Errorf("counting profiles: %w", err)
+	}
+
+	body, err := readResponseBody(resp.Body, resp.StatusCode)
+	if err != nil {
+		return err
+	}
+
+	var pageResp engageResponse
+	if err := json.Unmarshal(body, &pageResp); err != nil {
+		return fmt.Errorf("parsing profiles response: %w", err)
+	}
+	if err := pageResp.apiError(); err != nil {
+		return err
+	}
+
+	handled, err := handleJSONOutput(cmd, map[string]any{"total": pageResp.Total})
+	if err != nil {
+		return err
+	}
+	if handled {
+		return nil
+	}
+
+	s := getIO()
+	s.Printf("%d\n", pageResp.Total)
+	return nil
+}
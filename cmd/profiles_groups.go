@@ -1,12 +1,9 @@
 package cmd
 
 import (
-	"encoding/json"
-	"fmt"
 	"net/url"
 	"strconv"
 
-	"github.com/aviadshiber/mp/internal/client"
 	"github.com/spf13/cobra"
 )
 
@@ -17,6 +14,7 @@ func newProfilesGroupsCmd() *cobra.Command {
 		properties  string
 		limit       int
 		pageSize    int
+		dedup       bool
 	)
 
 	cmd := &cobra.Command{
@@ -40,7 +38,7 @@ Automatically paginates through all matching results unless a --limit is specifi
   # JSON output
   mp profiles groups --group-key companies --json`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runProfilesGroups(cmd, groupKey, where, properties, limit, pageSize)
+			return runProfilesGroups(cmd, groupKey, where, properties, limit, pageSize, dedup)
 		},
 	}
 
@@ -49,21 +47,24 @@ Automatically paginates through all matching results unless a --limit is specifi
 	cmd.Flags().StringVar(&properties, "properties", "", "Comma-separated output property names")
 	cmd.Flags().IntVar(&limit, "limit", 0, "Maximum total profiles to fetch (0 = all)")
 	cmd.Flags().IntVar(&pageSize, "page-size", 1000, "Profiles per page (max 1000)")
+	cmd.Flags().BoolVar(&dedup, "dedup", false, "Skip profiles whose distinct_id was already returned on an earlier page (best-effort)")
 
 	_ = cmd.MarkFlagRequired("group-key")
 
 	return cmd
 }
 
-func runProfilesGroups(cmd *cobra.Command, groupKey, where, properties string, limit, pageSize int) error {
-	if pageSize < 1 || pageSize > 1000 {
-		return fmt.Errorf("`--page-size` must be between 1 and 1000")
+func runProfilesGroups(cmd *cobra.Command, groupKey, where, properties string, limit, pageSize int, dedup bool) error {
+	pageSize, err := resolvePageSize(pageSize)
+	if err != nil {
+		return err
 	}
 
 	c, err := newClient()
 	if err != nil {
 		return err
 	}
+	defer reportRetries(c)
 
 	// Build base form parameters.
 	baseParams := url.Values{}
@@ -82,59 +83,23 @@ func runProfilesGroups(cmd *cobra.Command, groupKey, where, properties string, l
 	}
 	baseParams.Set("page_size", strconv.Itoa(pageSize))
 
+	var seen map[string]bool
+	if dedup {
+		seen = make(map[string]bool)
+	}
+
 	// Auto-paginate (same logic as profiles query).
 	var allResults []map[string]any
-	var sessionID string
-	page := 0
-	totalFromAPI := -1
-
-	for {
-		params := url.Values{}
-		for k, v := range baseParams {
-			params[k] = v
-		}
-		params.Set("page", strconv.Itoa(page))
-		if sessionID != "" {
-			params.Set("session_id", sessionID)
-		}
-
-		resp, err := c.Post(client.APIFamilyQuery, "/engage", params)
-		if err != nil {
-			return fmt.Errorf("querying group profiles (page %d): %w", page, err)
-		}
-
-		body, err := readResponseBody(resp.Body, resp.StatusCode)
-		if err != nil {
-			return err
-		}
-
-		var pageResp engageResponse
-		if err := json.Unmarshal(body, &pageResp); err != nil {
-			return fmt.Errorf("parsing group profiles response: %w", err)
-		}
-
-		if pageResp.Status != "ok" && pageResp.Status != "" {
-			return fmt.Errorf("engage API returned status %q", pageResp.Status)
-		}
-
-		allResults = append(allResults, pageResp.Results...)
-		sessionID = pageResp.SessionID
-		if totalFromAPI < 0 {
-			totalFromAPI = pageResp.Total
-		}
-
+	totalFromAPI, err := engagePages(c, baseParams, pageSize, "group profiles", func(page []map[string]any) (bool, error) {
+		allResults = appendUniqueProfiles(allResults, page, seen)
 		if limit > 0 && len(allResults) >= limit {
 			allResults = allResults[:limit]
-			break
+			return false, nil
 		}
-		if len(allResults) >= totalFromAPI {
-			break
-		}
-		if len(pageResp.Results) < pageSize {
-			break
-		}
-
-		page++
+		return true, nil
+	})
+	if err != nil {
+		return err
 	}
 
 	combined := map[string]any{
@@ -1,6 +1,10 @@
 package cmd
 
 import (
+	"fmt"
+	"net/url"
+	"strings"
+
 	"github.com/spf13/cobra"
 )
 
@@ -10,9 +14,44 @@ var queryCmd = &cobra.Command{
 	Long: `Run analytics queries against the Mixpanel Query API.
 
 Available subcommands let you query event segmentation, aggregate event counts,
-user properties, funnels, retention, and more.`,
+user properties, funnels, retention, and more.
+
+Commands that run a query accept --param key=value to pass an extra parameter to
+the Query API, for options mp does not wrap yet. Extra parameters are applied
+last, so they override any parameter mp sets itself; repeat the flag to set
+several parameters or to give one parameter several values. mp does not check
+them, so a misspelled or invalid parameter is ignored or rejected by the API.`,
 }
 
+// queryExtraParams holds the raw key=value pairs of --param.
+var queryExtraParams []string
+
 func init() {
 	rootCmd.AddCommand(queryCmd)
 }
+
+// addExtraParamsFlag registers --param on a command that passes it to the
+// Query API through applyExtraParams. Listing and lookup commands do not take
+// it: their responses are cached, and --param is not part of the cache key.
+func addExtraParamsFlag(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&queryExtraParams, "param", nil,
+		"Extra Query API parameter as key=value, overriding mp's own (repeatable)")
+}
+
+// applyExtraParams sets the --param parameters on params, replacing any
+// values mp already set for the same keys. Repeating a key adds values.
+func applyExtraParams(params url.Values) error {
+	extra := url.Values{}
+	for _, raw := range queryExtraParams {
+		key, value, ok := strings.Cut(raw, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return fmt.Errorf("invalid `--param` %q; expected key=value", raw)
+		}
+		extra.Add(key, value)
+	}
+	for key, values := range extra {
+		params[key] = values
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/aviadshiber/mp/internal/client"
+	"github.com/aviadshiber/mp/internal/output"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	queryCmd.AddCommand(newQueryCustomEventsCmd())
+}
+
+func newQueryCustomEventsCmd() *cobra.Command {
+	customEventsCmd := &cobra.Command{
+		Use:   "custom-events",
+		Short: "List custom events",
+		Long: `List the custom events defined in the project. Custom event IDs can be
+referenced by other queries. Mixpanel exposes no endpoint for a single custom
+event, so only "list" is available.`,
+	}
+
+	setRequestTimeout(customEventsCmd, metadataRequestTimeout)
+
+	customEventsCmd.AddCommand(newCustomEventsListCmd())
+	return customEventsCmd
+}
+
+func newCustomEventsListCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List all custom events in the project",
+		Long:  "List all custom events in the current project with their IDs and names.",
+		Example: `  # List custom events
+  mp query custom-events list
+
+  # JSON output
+  mp query custom-events list --json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCustomEventsList(cmd)
+		},
+	}
+	return cmd
+}
+
+func runCustomEventsList(cmd *cobra.Command) error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	if err := addProjectID(params); err != nil {
+		return err
+	}
+
+	body, err := cachedResponse("/custom_events", params, func(h http.Header) (*http.Response, error) {
+		resp, err := c.GetWithHeader(client.APIFamilyQuery, "/custom_events", params, h)
+		if err != nil {
+			return nil, fmt.Errorf("listing custom events: %w", err)
+		}
+		return resp, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var customEvents []map[string]any
+	if err := json.Unmarshal(body, &customEvents); err != nil {
+		return fmt.Errorf("parsing custom events response: %w", err)
+	}
+
+	handled, err := handleJSONOutput(cmd, customEvents)
+	if err != nil {
+		return err
+	}
+	if handled {
+		return nil
+	}
+
+	return renderCustomEventsList(customEvents)
+}
+
+func renderCustomEventsList(customEvents []map[string]any) error {
+	s := getIO()
+
+	if len(customEvents) == 0 {
+		return emptyResult(s, "No custom events found.")
+	}
+
+	// Sort by ID for consistent output.
+	sort.Slice(customEvents, func(i, j int) bool {
+		idI, _ := customEvents[i]["id"].(float64)
+		idJ, _ := customEvents[j]["id"].(float64)
+		return idI < idJ
+	})
+
+	headers := []string{"ID", "NAME"}
+	rows := make([][]string, 0, len(customEvents))
+
+	for _, e := range customEvents {
+		id := fmt.Sprintf("%.0f", e["id"])
+		name, _ := e["name"].(string)
+		rows = append(rows, []string{id, name})
+	}
+
+	output.PrintTable(s.Out, headers, rows, s.IsTerminal())
+	s.Footer("%s", pluralize(len(rows), "custom event"))
+	return nil
+}
@@ -3,8 +3,12 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/url"
+	"os"
+	"path/filepath"
 	"sort"
+	"strings"
 
 	"github.com/aviadshiber/mp/internal/client"
 	"github.com/aviadshiber/mp/internal/output"
@@ -19,16 +23,38 @@ func newQueryEventsCmd() *cobra.Command {
 	var (
 		event     string
 		queryType string
-		unit      string
-		from      string
-		to        string
+		transpose bool
+		totals    bool
+		rolling   int
+		delta     bool
+		outFile   string
+		split     bool
+		qf        *queryFlags
 	)
 
 	cmd := &cobra.Command{
 		Use:   "events",
 		Short: "Query aggregate event counts over time",
 		Long: `Query aggregate event counts from the Mixpanel analytics API. Returns counts
-for one or more events broken down by the specified time unit.`,
+for one or more events broken down by the specified time unit.
+
+With --type average and no --on, the /events endpoint returns the average
+number of times each user performed the event. To aggregate a numeric
+property instead, pass --on with --type average or --type sum; each event is
+then queried through /segmentation/average or /segmentation/sum (unit hour or
+day) and the values are shown in the same per-event columns. Averages cannot
+be summed, so --totals is rejected with --type average.
+
+--rolling N and --delta add derived columns after each event's column: a
+trailing N-period moving average, and the change from the previous period as
+"+12 (+8.3%)". Both only affect the table; --json returns the raw values.
+
+--output-file writes the table as CSV to a file instead of printing it. Add
+--split-by-event to write one CSV per event instead, each with a DATE column
+and that event's columns. The --output-file value is then a directory (if it
+exists or ends in a slash), giving <dir>/<event>.csv, or a file name prefix,
+giving <prefix>_<event>.csv. Characters other than letters, digits, ".", "-",
+and "_" in event names are replaced with "_" in file names.`,
 		Example: `  # Daily signups and logins for January 2024
   mp query events --event "Signup,Login" --type general --unit day \
     --from 2024-01-01 --to 2024-01-31
@@ -41,38 +67,96 @@ for one or more events broken down by the specified time unit.`,
   mp query events --event "Signup" --type general --unit month \
     --from 2024-01-01 --to 2024-12-31 --json
 
+  # Events as rows and dates as columns
+  mp query events --event "Signup,Login" --type general --unit day \
+    --from 2024-01-01 --to 2024-01-07 --transpose
+
+  # Append a TOTAL row summing each event across the range
+  mp query events --event "Signup,Login" --type general --unit day \
+    --from 2024-01-01 --to 2024-03-31 --totals
+
+  # Daily signups with a 7-day moving average column
+  mp query events --event "Signup" --type general --unit day \
+    --from 2024-01-01 --to 2024-03-31 --rolling 7
+
+  # Week-over-week change per event
+  mp query events --event "Signup,Login" --type general --unit week \
+    --from 2024-01-01 --to 2024-03-31 --delta
+
+  # Daily average purchase amount per event
+  mp query events --event "Purchase,Refund" --type average --unit day \
+    --from 2024-01-01 --to 2024-01-31 --on 'properties["amount"]'
+
+  # One CSV per event: exports/Signup.csv and exports/Login.csv
+  mp query events --event "Signup,Login" --type general --unit day \
+    --from 2024-01-01 --to 2024-01-31 --output-file exports/ --split-by-event
+
   # Filter with jq
   mp query events --event "Signup" --type general --unit day \
-    --from 2024-01-01 --to 2024-01-31 --json --jq '.data.values'`,
+    --from 2024-01-01 --to 2024-01-31 --jq '.data.values'`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runQueryEvents(cmd, event, queryType, unit, from, to)
+			if rolling < 0 {
+				return fmt.Errorf("`--rolling` must be a positive number of periods")
+			}
+			if split && outFile == "" {
+				return fmt.Errorf("`--split-by-event` requires `--output-file`")
+			}
+			if outFile != "" && jsonOutputRequested(cmd) {
+				return fmt.Errorf("`--output-file` writes CSV and cannot be combined with `--json`")
+			}
+			opts := eventsTableOptions{transpose: transpose, totals: totals, rolling: rolling, delta: delta, outputFile: outFile, splitByEvent: split}
+			return runQueryEvents(cmd, event, queryType, qf.unit, qf.from, qf.to, qf.on, opts)
 		},
 	}
 
 	cmd.Flags().StringVar(&event, "event", "", "Comma-separated event names (required)")
-	cmd.Flags().StringVar(&queryType, "type", "", "Aggregation type: general, unique, average (required)")
-	cmd.Flags().StringVar(&unit, "unit", "", "Time unit: minute, hour, day, week, month (required)")
-	cmd.Flags().StringVar(&from, "from", "", "Start date yyyy-mm-dd (required)")
-	cmd.Flags().StringVar(&to, "to", "", "End date yyyy-mm-dd (required)")
+	cmd.Flags().StringVar(&queryType, "type", "", "Aggregation type: general, unique, average; sum with --on (required)")
+	qf = addCommonQueryFlags(cmd, queryFlagSpec{
+		units:        []string{"minute", "hour", "day", "week", "month"},
+		unitRequired: true,
+		on:           "Numeric property expression to average or sum (with --type average or sum)",
+	})
+	cmd.Flags().BoolVar(&transpose, "transpose", false, "Show events as rows and dates as columns")
+	cmd.Flags().BoolVar(&totals, "totals", false, "Append a TOTAL row summing each event (table output only; not with --type average)")
+	cmd.Flags().IntVar(&rolling, "rolling", 0, "Add a trailing N-period moving average column per event (table output only)")
+	cmd.Flags().BoolVar(&delta, "delta", false, "Add a column with the change from the previous period per event (table output only)")
+	cmd.Flags().StringVar(&outFile, "output-file", "", "Write the table as CSV to this file instead of stdout")
+	cmd.Flags().BoolVar(&split, "split-by-event", false, "With --output-file, write one CSV per event using it as a directory or file name prefix")
 
-	_ = cmd.MarkFlagRequired("event")
+	addEventPicker(cmd, &event)
 	_ = cmd.MarkFlagRequired("type")
-	_ = cmd.MarkFlagRequired("unit")
-	_ = cmd.MarkFlagRequired("from")
-	_ = cmd.MarkFlagRequired("to")
 
+	addExtraParamsFlag(cmd)
 	return cmd
 }
 
-func runQueryEvents(cmd *cobra.Command, event, queryType, unit, from, to string) error {
+func runQueryEvents(cmd *cobra.Command, event, queryType, unit, from, to, on string, opts eventsTableOptions) error {
+	events := splitCSV(event)
+	if len(events) == 0 {
+		return fmt.Errorf("`--event` must specify at least one event name")
+	}
+
+	if on == "" && queryType == "sum" {
+		return fmt.Errorf("`--type sum` requires `--on`")
+	}
+	if on != "" && queryType != "average" && queryType != "sum" {
+		return fmt.Errorf("`--on` requires `--type average` or `--type sum`")
+	}
+	if opts.totals && queryType == "average" {
+		return fmt.Errorf("`--totals` sums each event's values and cannot be used with `--type average`; a sum of averages is not meaningful")
+	}
+
 	c, err := newClient()
 	if err != nil {
 		return err
 	}
 
-	events := splitCSV(event)
-	if len(events) == 0 {
-		return fmt.Errorf("`--event` must specify at least one event name")
+	if on != "" {
+		result, err := fetchEventMeasures(c, events, queryType, unit, from, to, on)
+		if err != nil {
+			return err
+		}
+		return writeEventsResult(cmd, result, events, opts)
 	}
 
 	params := url.Values{}
@@ -85,6 +169,10 @@ func runQueryEvents(cmd *cobra.Command, event, queryType, unit, from, to string)
 	params.Set("from_date", from)
 	params.Set("to_date", to)
 
+	if err := applyExtraParams(params); err != nil {
+		return err
+	}
+
 	resp, err := c.Get(client.APIFamilyQuery, "/events", params)
 	if err != nil {
 		return fmt.Errorf("querying events: %w", err)
@@ -99,6 +187,19 @@ func runQueryEvents(cmd *cobra.Command, event, queryType, unit, from, to string)
 	if err := json.Unmarshal(body, &result); err != nil {
 		return fmt.Errorf("parsing events response: %w", err)
 	}
+	if err := checkAPIError(result); err != nil {
+		return err
+	}
+
+	return writeEventsResult(cmd, result, events, opts)
+}
+
+// writeEventsResult prints result as JSON or a table, or writes it to the
+// CSV file(s) selected by opts.outputFile.
+func writeEventsResult(cmd *cobra.Command, result map[string]any, events []string, opts eventsTableOptions) error {
+	if opts.outputFile != "" {
+		return writeEventsCSVFiles(result, opts)
+	}
 
 	handled, err := handleJSONOutput(cmd, result)
 	if err != nil {
@@ -108,61 +209,290 @@ func runQueryEvents(cmd *cobra.Command, event, queryType, unit, from, to string)
 		return nil
 	}
 
-	return renderEventsTable(result, events)
+	return renderEventsTable(result, events, opts)
 }
 
-// renderEventsTable renders event query results as a table with one column per event.
+// fetchEventMeasures aggregates the numeric expression on for each event
+// through /segmentation/<measure> and reshapes the per-event results into the
+// /events response shape so they render in the usual per-event columns. The
+// parallel requests share c and so its rate limiter.
+func fetchEventMeasures(c *client.Client, events []string, measure, unit, from, to, on string) (map[string]any, error) {
+	perEvent := make([]map[string]any, len(events))
+	err := forEachConcurrent(len(events), func(i int) error {
+		params, err := segmentationParams(events[i], from, to, on, unit, "")
+		if err != nil {
+			return err
+		}
+		result, err := fetchSegmentation(c, "/segmentation/"+measure, params, "segmentation "+measure+" for "+events[i])
+		if err != nil {
+			return err
+		}
+		perEvent[i], _ = result["results"].(map[string]any)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dateSet := make(map[string]bool)
+	values := make(map[string]any, len(events))
+	for i, ev := range events {
+		for d := range perEvent[i] {
+			dateSet[d] = true
+		}
+		values[ev] = perEvent[i]
+	}
+
+	dates := make([]string, 0, len(dateSet))
+	for d := range dateSet {
+		dates = append(dates, d)
+	}
+	sort.Strings(dates)
+	series := make([]any, len(dates))
+	for i, d := range dates {
+		series[i] = d
+	}
+
+	return map[string]any{
+		"data": map[string]any{
+			"series": series,
+			"values": values,
+		},
+	}, nil
+}
+
+// eventsTableOptions controls how renderEventsTable presents values. It only
+// affects table output; JSON output always carries the raw response.
+type eventsTableOptions struct {
+	// transpose shows events as rows and dates as columns.
+	transpose bool
+	// totals appends a TOTAL row summing each event's numeric values.
+	totals bool
+	// rolling, when positive, adds a trailing moving average column after
+	// each event column.
+	rolling int
+	// delta adds a column after each event column with the absolute and
+	// percent change from the previous date.
+	delta bool
+	// outputFile, when set, receives the table as CSV instead of stdout.
+	outputFile string
+	// splitByEvent writes one CSV per event, treating outputFile as a
+	// directory or file name prefix.
+	splitByEvent bool
+}
+
+// rollingAverages returns the trailing window-period mean of evData for each
+// of dates, in order. Missing values count as zero, the first window-1 dates
+// render empty, and means are rounded to two decimals.
+func rollingAverages(evData map[string]any, dates []string, window int) []string {
+	out := make([]string, len(dates))
+	sum := 0.0
+	vals := make([]float64, len(dates))
+	for i, date := range dates {
+		vals[i], _ = output.NumericValue(evData[date])
+		sum += vals[i]
+		if i >= window {
+			sum -= vals[i-window]
+		}
+		if i+1 >= window {
+			out[i] = output.FormatNumber(math.Round(sum/float64(window)*100) / 100)
+		}
+	}
+	return out
+}
+
+// periodDeltas returns the change of evData from the previous date for each of
+// dates, formatted as "+12 (+8.3%)". Missing values count as zero, like in the
+// table, and the first date renders empty. When the previous value is zero
+// only the absolute change is shown.
+func periodDeltas(evData map[string]any, dates []string) []string {
+	out := make([]string, len(dates))
+	for i := 1; i < len(dates); i++ {
+		prev, _ := output.NumericValue(evData[dates[i-1]])
+		cur, _ := output.NumericValue(evData[dates[i]])
+		out[i] = formatChange(cur, prev)
+	}
+	return out
+}
+
+// formatChange formats the change from prev to cur as "+12 (+8.3%)", or just
+// the absolute change when prev is zero.
+func formatChange(cur, prev float64) string {
+	change := signedNumber(cur - prev)
+	if prev != 0 {
+		change += fmt.Sprintf(" (%+.1f%%)", (cur-prev)/math.Abs(prev)*100)
+	}
+	return change
+}
+
+// signedNumber formats f like formatNumber with an explicit "+" for positive
+// values.
+func signedNumber(f float64) string {
+	if f > 0 {
+		return "+" + output.FormatNumber(f)
+	}
+	return output.FormatNumber(f)
+}
+
+// eventsTableData returns the dates and per-event values of an /events
+// response, or ok=false when it does not have the expected shape.
 // Response shape: {"data": {"series": [...dates], "values": {eventName: {date: count}}}}
-func renderEventsTable(result map[string]any, requestedEvents []string) error {
+func eventsTableData(result map[string]any) (dates []string, values map[string]any, ok bool) {
+	data, ok := result["data"].(map[string]any)
+	if !ok {
+		return nil, nil, false
+	}
+	seriesRaw, _ := data["series"].([]any)
+	values, _ = data["values"].(map[string]any)
+
+	dates = make([]string, 0, len(seriesRaw))
+	for _, d := range seriesRaw {
+		dates = append(dates, fmt.Sprintf("%v", d))
+	}
+	return dates, values, true
+}
+
+// renderEventsTable renders event query results as a table with one column per event.
+func renderEventsTable(result map[string]any, requestedEvents []string, opts eventsTableOptions) error {
 	s := getIO()
 
-	data, ok := result["data"].(map[string]any)
+	dates, valuesRaw, ok := eventsTableData(result)
 	if !ok {
 		return output.PrintJSON(s.Out, result)
 	}
+	if len(dates) == 0 {
+		return emptyResult(s, "No data returned.")
+	}
 
-	seriesRaw, _ := data["series"].([]any)
-	valuesRaw, _ := data["values"].(map[string]any)
+	headers, rows := eventsTableRows(valuesRaw, dates, opts)
+	output.PrintTable(s.Out, headers, rows, s.IsTerminal())
+	return nil
+}
 
-	if len(seriesRaw) == 0 {
-		s.Printf("No data returned.\n")
-		return nil
+// writeEventsCSVFiles writes the events table as CSV to opts.outputFile, or
+// with opts.splitByEvent, one CSV per event named by eventsCSVPath.
+func writeEventsCSVFiles(result map[string]any, opts eventsTableOptions) error {
+	s := getIO()
+
+	dates, valuesRaw, ok := eventsTableData(result)
+	if !ok || len(dates) == 0 {
+		return emptyResult(s, "No data returned.")
 	}
 
-	dates := make([]string, 0, len(seriesRaw))
-	for _, d := range seriesRaw {
-		dates = append(dates, fmt.Sprintf("%v", d))
+	if !opts.splitByEvent {
+		headers, rows := eventsTableRows(valuesRaw, dates, opts)
+		if err := writeCSVFile(opts.outputFile, headers, rows); err != nil {
+			return err
+		}
+		s.Printf("%s Wrote %s\n", s.Success(""), opts.outputFile)
+		return nil
 	}
 
-	// Determine event columns: use the order from the response values,
-	// sorted for consistency.
-	eventNames := make([]string, 0, len(valuesRaw))
+	names := make([]string, 0, len(valuesRaw))
 	for name := range valuesRaw {
-		eventNames = append(eventNames, name)
-	}
-	sort.Strings(eventNames)
-
-	// Build headers: DATE + one column per event.
-	headers := make([]string, 0, 1+len(eventNames))
-	headers = append(headers, "DATE")
-	headers = append(headers, eventNames...)
-
-	rows := make([][]string, 0, len(dates))
-	for _, date := range dates {
-		row := make([]string, 0, 1+len(eventNames))
-		row = append(row, date)
-		for _, name := range eventNames {
-			val := "0"
-			if evData, ok := valuesRaw[name].(map[string]any); ok {
-				if v, exists := evData[date]; exists {
-					val = fmt.Sprintf("%v", v)
-				}
-			}
-			row = append(row, val)
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	paths := make(map[string]string, len(names))
+	for _, name := range names {
+		path := eventsCSVPath(opts.outputFile, name)
+		if other, dup := paths[path]; dup {
+			return fmt.Errorf("events %q and %q both map to file %s", other, name, path)
 		}
-		rows = append(rows, row)
+		paths[path] = name
 	}
 
-	output.PrintTable(s.Out, headers, rows, s.IsTerminal())
+	for _, name := range names {
+		headers, rows := eventsTableRows(map[string]any{name: valuesRaw[name]}, dates, opts)
+		if err := writeCSVFile(eventsCSVPath(opts.outputFile, name), headers, rows); err != nil {
+			return err
+		}
+	}
+	s.Printf("%s Wrote %s\n", s.Success(""), pluralize(len(names), "file"))
+	return nil
+}
+
+// eventsCSVPath returns the file for event's CSV. target is a directory when
+// it ends in a path separator or is an existing directory, and a file name
+// prefix otherwise.
+func eventsCSVPath(target, event string) string {
+	file := sanitizeFileName(event) + ".csv"
+	if strings.HasSuffix(target, "/") || strings.HasSuffix(target, string(filepath.Separator)) {
+		return filepath.Join(target, file)
+	}
+	if info, err := os.Stat(target); err == nil && info.IsDir() {
+		return filepath.Join(target, file)
+	}
+	return target + "_" + file
+}
+
+// sanitizeFileName replaces every character of name other than ASCII
+// letters, digits, ".", "-", and "_" with "_", so event names such as
+// "$session/start" make safe file names.
+func sanitizeFileName(name string) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+	if strings.Trim(safe, ".") == "" {
+		safe = strings.Repeat("_", max(len(safe), 1))
+	}
+	return safe
+}
+
+// writeCSVFile writes headers and rows as CSV to a new file at path, creating
+// its directory if needed.
+func writeCSVFile(path string, headers []string, rows [][]string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating output file: %w", err)
+	}
+	if err := output.PrintCSV(f, headers, rows); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("closing output file: %w", err)
+	}
 	return nil
 }
+
+// eventsTableRows builds the events table for the events in valuesRaw over
+// dates, including the extra columns and rows requested by opts.
+func eventsTableRows(valuesRaw map[string]any, dates []string, opts eventsTableOptions) ([]string, [][]string) {
+	// Each event's column is followed by its moving average and change
+	// columns when --rolling and --delta are set.
+	var derived []output.Map2DColumn
+	if opts.rolling > 0 {
+		derived = append(derived, output.Map2DColumn{
+			Header: func(name string) string { return fmt.Sprintf("%s (avg %d)", name, opts.rolling) },
+			Cells: func(name string) []string {
+				evData, _ := valuesRaw[name].(map[string]any)
+				return rollingAverages(evData, dates, opts.rolling)
+			},
+		})
+	}
+	if opts.delta {
+		derived = append(derived, output.Map2DColumn{
+			Header: func(name string) string { return name + " (change)" },
+			Cells: func(name string) []string {
+				evData, _ := valuesRaw[name].(map[string]any)
+				return periodDeltas(evData, dates)
+			},
+		})
+	}
+
+	return output.Map2D(dates, valuesRaw, output.Map2DOptions{
+		SeriesHeader: "EVENT",
+		Transpose:    opts.transpose,
+		Totals:       opts.totals,
+		Derived:      derived,
+	})
+}
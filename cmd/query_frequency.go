@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"sort"
+	"strings"
 
 	"github.com/aviadshiber/mp/internal/client"
 	"github.com/aviadshiber/mp/internal/output"
@@ -17,21 +18,27 @@ func init() {
 
 func newQueryFrequencyCmd() *cobra.Command {
 	var (
-		from          string
-		to            string
-		unit          string
 		addictionUnit string
 		event         string
-		where         string
-		on            string
-		limit         int
+		percent       bool
+		qf            *queryFlags
 	)
 
 	cmd := &cobra.Command{
 		Use:   "frequency",
 		Short: "Query event frequency (addiction) data",
 		Long: `Query event frequency data from the Mixpanel analytics API. Shows how often
-users perform an event within a given time period (also known as "addiction" report).`,
+users perform an event within a given time period (also known as an
+"addiction" report).
+
+For each date, the /retention/addiction endpoint returns a list of user counts
+where the Nth entry is the number of users who performed the event in at least
+N distinct --addiction-unit periods within that --unit. The table labels the
+columns accordingly, e.g. "1+ HOURS", "2+ HOURS", so the counts are cumulative
+and decrease from left to right.
+
+--percent shows each count as a share of the date's total users, which is the
+first column: everyone who performed the event at least once.`,
 		Example: `  # Daily frequency breakdown for January 2024
   mp query frequency --from 2024-01-01 --to 2024-01-31 \
     --unit day --addiction-unit hour
@@ -44,32 +51,36 @@ users perform an event within a given time period (also known as "addiction" rep
   mp query frequency --from 2024-01-01 --to 2024-01-31 \
     --unit day --addiction-unit hour --on 'properties["country"]'
 
+  # Share of each day's users who were active in 3+ distinct hours
+  mp query frequency --from 2024-01-01 --to 2024-01-31 \
+    --unit day --addiction-unit hour --percent
+
   # JSON output
   mp query frequency --from 2024-01-01 --to 2024-01-31 \
     --unit day --addiction-unit hour --json`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runQueryFrequency(cmd, from, to, unit, addictionUnit, event, where, on, limit)
+			return runQueryFrequency(cmd, qf.from, qf.to, qf.unit, addictionUnit, event, qf.where, qf.on, qf.limit, percent)
 		},
 	}
 
-	cmd.Flags().StringVar(&from, "from", "", "Start date yyyy-mm-dd (required)")
-	cmd.Flags().StringVar(&to, "to", "", "End date yyyy-mm-dd (required)")
-	cmd.Flags().StringVar(&unit, "unit", "", "Time unit: day, week, month (required)")
+	qf = addCommonQueryFlags(cmd, queryFlagSpec{
+		units:        []string{"day", "week", "month"},
+		unitRequired: true,
+		on:           breakdownHelp,
+		where:        filterHelp,
+		limit:        "breakdown values",
+	})
 	cmd.Flags().StringVar(&addictionUnit, "addiction-unit", "", "Frequency unit: hour, day (required)")
 	cmd.Flags().StringVar(&event, "event", "", "Event name to analyze")
-	cmd.Flags().StringVar(&where, "where", "", "Filter expression")
-	cmd.Flags().StringVar(&on, "on", "", "Property expression for breakdown")
-	cmd.Flags().IntVar(&limit, "limit", 0, "Maximum number of breakdown values")
+	cmd.Flags().BoolVar(&percent, "percent", false, "Show each count as a percentage of the date's total users (table output only)")
 
-	_ = cmd.MarkFlagRequired("from")
-	_ = cmd.MarkFlagRequired("to")
-	_ = cmd.MarkFlagRequired("unit")
 	_ = cmd.MarkFlagRequired("addiction-unit")
 
+	addExtraParamsFlag(cmd)
 	return cmd
 }
 
-func runQueryFrequency(cmd *cobra.Command, from, to, unit, addictionUnit, event, where, on string, limit int) error {
+func runQueryFrequency(cmd *cobra.Command, from, to, unit, addictionUnit, event, where, on string, limit int, percent bool) error {
 	c, err := newClient()
 	if err != nil {
 		return err
@@ -97,6 +108,10 @@ func runQueryFrequency(cmd *cobra.Command, from, to, unit, addictionUnit, event,
 		params.Set("limit", fmt.Sprintf("%d", limit))
 	}
 
+	if err := applyExtraParams(params); err != nil {
+		return err
+	}
+
 	resp, err := c.Get(client.APIFamilyQuery, "/retention/addiction", params)
 	if err != nil {
 		return fmt.Errorf("querying frequency: %w", err)
@@ -111,6 +126,9 @@ func runQueryFrequency(cmd *cobra.Command, from, to, unit, addictionUnit, event,
 	if err := json.Unmarshal(body, &result); err != nil {
 		return fmt.Errorf("parsing frequency response: %w", err)
 	}
+	if err := checkAPIError(result); err != nil {
+		return err
+	}
 
 	handled, err := handleJSONOutput(cmd, result)
 	if err != nil {
@@ -120,12 +138,35 @@ func runQueryFrequency(cmd *cobra.Command, from, to, unit, addictionUnit, event,
 		return nil
 	}
 
-	return renderFrequencyTable(result)
+	return renderFrequencyTable(result, addictionUnit, percent)
+}
+
+// frequencyColumnLabel labels the frequency bucket at index i, which counts
+// users who performed the event in at least i+1 distinct addiction units,
+// e.g. "1+ HOUR" or "3+ DAYS".
+func frequencyColumnLabel(i int, addictionUnit string) string {
+	unit := strings.ToUpper(addictionUnit)
+	if i > 0 {
+		unit += "S"
+	}
+	return fmt.Sprintf("%d+ %s", i+1, unit)
+}
+
+// frequencyTotal returns the total users of a date's frequency buckets. The
+// buckets are cumulative, so it is the first one (users active at least once).
+func frequencyTotal(buckets []any) float64 {
+	if len(buckets) == 0 {
+		return 0
+	}
+	n, _ := output.NumericValue(buckets[0])
+	return n
 }
 
-// renderFrequencyTable renders frequency data as a table.
+// renderFrequencyTable renders frequency data as a table with one column per
+// bucket, labeled using addictionUnit. With percent, each count is shown as a
+// share of the date's total users.
 // Response shape: {"data": {"2024-01-01": [50, 30, 20, 10]}}
-func renderFrequencyTable(result map[string]any) error {
+func renderFrequencyTable(result map[string]any, addictionUnit string, percent bool) error {
 	s := getIO()
 
 	data, ok := result["data"].(map[string]any)
@@ -134,8 +175,7 @@ func renderFrequencyTable(result map[string]any) error {
 	}
 
 	if len(data) == 0 {
-		s.Printf("No frequency data returned.\n")
-		return nil
+		return emptyResult(s, "No frequency data returned.")
 	}
 
 	// Collect and sort dates, find max frequency buckets.
@@ -149,11 +189,11 @@ func renderFrequencyTable(result map[string]any) error {
 	}
 	sort.Strings(dates)
 
-	// Build headers: DATE | FREQ 0 | FREQ 1 | ...
+	// Build headers: DATE | 1+ HOUR | 2+ HOURS | ...
 	headers := make([]string, 0, 1+maxBuckets)
 	headers = append(headers, "DATE")
 	for i := 0; i < maxBuckets; i++ {
-		headers = append(headers, fmt.Sprintf("FREQ %d", i))
+		headers = append(headers, frequencyColumnLabel(i, addictionUnit))
 	}
 
 	rows := make([][]string, 0, len(dates))
@@ -163,11 +203,16 @@ func renderFrequencyTable(result map[string]any) error {
 			continue
 		}
 
+		total := frequencyTotal(buckets)
 		row := make([]string, 0, 1+maxBuckets)
 		row = append(row, date)
 		for i := 0; i < maxBuckets; i++ {
 			val := ""
-			if i < len(buckets) {
+			switch {
+			case i >= len(buckets):
+			case percent:
+				val = output.FormatPercent(buckets[i], total)
+			default:
 				val = fmt.Sprintf("%v", buckets[i])
 			}
 			row = append(row, val)
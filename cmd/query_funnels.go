@@ -3,8 +3,10 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
 	"sort"
+	"strings"
 
 	"github.com/aviadshiber/mp/internal/client"
 	"github.com/aviadshiber/mp/internal/output"
@@ -34,24 +36,53 @@ Use "mp query funnels list" to see available funnels and their IDs.`,
 func newFunnelsQueryCmd() *cobra.Command {
 	var (
 		funnelID   int
-		from       string
-		to         string
+		stepsList  string
+		stepFlags  []string
+		steps      []string
 		length     int
 		lengthUnit string
-		unit       string
-		on         string
-		where      string
-		limit      int
+		trends     bool
+		date       string
+		allDates   bool
+		cmpFrom    string
+		cmpTo      string
+		qf         *queryFlags
 	)
 
 	cmd := &cobra.Command{
 		Use:   "query",
-		Short: "Query a specific funnel by ID",
-		Long: `Query a specific funnel by its ID. Returns step-by-step conversion data
-broken down by date.`,
+		Short: "Query a saved funnel by ID, or an ad hoc funnel of steps",
+		Long: `Query a saved funnel by its ID, or an ad hoc funnel defined by its steps.
+Returns step-by-step conversion data broken down by date. When neither
+--funnel-id nor --steps is given in an interactive terminal, mp lists the
+saved funnels and prompts you to pick one.
+
+An ad hoc funnel is given as --steps with the event names separated by ";",
+or as one --step per event, in order. It needs at least two steps and cannot
+be combined with --funnel-id.
+
+By default the table shows the step breakdown for the latest date; use --date to
+pick another date in the range. Use --trends to show the overall conversion
+rate for every date in the range instead. Use --all-dates to stream every
+date's steps as JSON Lines, one object per date and step:
+
+  {"date": "...", "step": 1, "event": "...", "count": 0, "overall_conv_ratio": 0, "step_conv_ratio": 0}
+
+Use --compare-from and --compare-to to run the same funnel over a second date
+range and show both side by side with the change. Step counts are summed over
+the dates in each range, so a user who enters the funnel on several dates is
+counted once per date. With --json, the output is
+{"current": {"from", "to", "result"}, "compare": {"from", "to", "result"}}.`,
 		Example: `  # Query funnel for January 2024
   mp query funnels query --funnel-id 7509 --from 2024-01-01 --to 2024-01-31
 
+  # Ad hoc funnel without a saved ID
+  mp query funnels query --steps 'Signup;Activate;Purchase' --from 2024-01-01 --to 2024-01-31
+
+  # Same, one flag per step
+  mp query funnels query --step Signup --step Activate --step Purchase \
+    --from 2024-01-01 --to 2024-01-31
+
   # With conversion window and time unit
   mp query funnels query --funnel-id 7509 --from 2024-01-01 --to 2024-01-31 \
     --length 14 --length-unit day --unit week
@@ -60,76 +91,216 @@ broken down by date.`,
   mp query funnels query --funnel-id 7509 --from 2024-01-01 --to 2024-01-31 \
     --on 'properties["country"]'
 
+  # Step breakdown for a specific date in the range
+  mp query funnels query --funnel-id 7509 --from 2024-01-01 --to 2024-01-31 \
+    --date 2024-01-15
+
+  # Overall conversion rate per date
+  mp query funnels query --funnel-id 7509 --from 2024-01-01 --to 2024-01-31 --trends
+
+  # January compared with December
+  mp query funnels query --funnel-id 7509 --from 2024-01-01 --to 2024-01-31 \
+    --compare-from 2023-12-01 --compare-to 2023-12-31
+
+  # Every date's steps as JSON Lines
+  mp query funnels query --funnel-id 7509 --from 2024-01-01 --to 2024-01-31 --all-dates
+
   # JSON output
   mp query funnels query --funnel-id 7509 --from 2024-01-01 --to 2024-01-31 --json`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runFunnelsQuery(cmd, funnelID, from, to, length, lengthUnit, unit, on, where, limit)
+			if allDates && (trends || date != "" || jsonOutputRequested(cmd)) {
+				return fmt.Errorf("`--all-dates` cannot be combined with `--trends`, `--date`, or `--json`")
+			}
+			if (cmpFrom == "") != (cmpTo == "") {
+				return fmt.Errorf("`--compare-from` and `--compare-to` must be used together")
+			}
+			if cmpFrom != "" && (trends || date != "" || allDates) {
+				return fmt.Errorf("`--compare-from` cannot be combined with `--trends`, `--date`, or `--all-dates`")
+			}
+			if err := validateDateFlags("compare-from", cmpFrom, "compare-to", cmpTo); err != nil {
+				return err
+			}
+			if err := validateLimit(qf.limit, maxFunnelsLimit); err != nil {
+				return err
+			}
+			opts := funnelTableOptions{trends: trends, date: date, allDates: allDates}
+			q := funnelQuery{funnelID: funnelID, steps: steps, length: length, lengthUnit: lengthUnit, unit: qf.unit, on: qf.on, where: qf.where, limit: qf.limit}
+			if cmpFrom != "" {
+				return runFunnelsCompare(cmd, q, qf.from, qf.to, cmpFrom, cmpTo)
+			}
+			return runFunnelsQuery(cmd, q, qf.from, qf.to, opts)
 		},
 	}
 
-	cmd.Flags().IntVar(&funnelID, "funnel-id", 0, "Funnel ID (required; use 'funnels list' to find IDs)")
-	cmd.Flags().StringVar(&from, "from", "", "Start date yyyy-mm-dd (required)")
-	cmd.Flags().StringVar(&to, "to", "", "End date yyyy-mm-dd (required)")
+	cmd.Flags().IntVar(&funnelID, "funnel-id", 0, "Saved funnel ID (prompts in a terminal if neither it nor --steps is given; use 'funnels list' to find IDs)")
+	cmd.Flags().StringVar(&stepsList, "steps", "", "Ad hoc funnel steps as event names separated by \";\"")
+	cmd.Flags().StringArrayVar(&stepFlags, "step", nil, "Ad hoc funnel step event name (repeatable, in order)")
+	cmd.MarkFlagsMutuallyExclusive("funnel-id", "steps", "step")
+	qf = addCommonQueryFlags(cmd, queryFlagSpec{
+		units:    []string{"day", "week", "month"},
+		on:       breakdownHelp,
+		where:    filterHelp,
+		limit:    "breakdown values",
+		maxLimit: maxFunnelsLimit,
+	})
 	cmd.Flags().IntVar(&length, "length", 0, "Conversion window length")
 	cmd.Flags().StringVar(&lengthUnit, "length-unit", "", "Conversion window unit: second, minute, hour, day")
-	cmd.Flags().StringVar(&unit, "unit", "", "Time unit: day, week, month")
-	cmd.Flags().StringVar(&on, "on", "", "Property expression for breakdown")
-	cmd.Flags().StringVar(&where, "where", "", "Filter expression")
-	cmd.Flags().IntVar(&limit, "limit", 0, "Maximum number of breakdown values (max 10000, default 255)")
-
-	_ = cmd.MarkFlagRequired("funnel-id")
-	_ = cmd.MarkFlagRequired("from")
-	_ = cmd.MarkFlagRequired("to")
+	cmd.Flags().BoolVar(&trends, "trends", false, "Show overall conversion per date instead of the step breakdown")
+	cmd.Flags().StringVar(&date, "date", "", "Date yyyy-mm-dd whose step breakdown to show (default: latest)")
+	cmd.Flags().BoolVar(&allDates, "all-dates", false, "Stream every date's steps as JSON Lines")
+	cmd.Flags().StringVar(&cmpFrom, "compare-from", "", "Start date yyyy-mm-dd of a second range to compare against")
+	cmd.Flags().StringVar(&cmpTo, "compare-to", "", "End date yyyy-mm-dd of a second range to compare against")
+
+	chainPreRunE(cmd, func() error {
+		var err error
+		steps, err = parseFunnelSteps(stepsList, stepFlags)
+		return err
+	})
+	addFunnelPicker(cmd, &funnelID, &steps)
 
+	addExtraParamsFlag(cmd)
 	return cmd
 }
 
-func runFunnelsQuery(cmd *cobra.Command, funnelID int, from, to string, length int, lengthUnit, unit, on, where string, limit int) error {
-	c, err := newClient()
-	if err != nil {
-		return err
-	}
+// funnelQuery holds the funnel query parameters other than the date range.
+type funnelQuery struct {
+	funnelID   int
+	steps      []string // event names of an ad hoc funnel, used instead of funnelID
+	length     int
+	lengthUnit string
+	unit       string
+	on         string
+	where      string
+	limit      int
+}
 
+// params builds the /funnels query parameters for the range from-to.
+func (q funnelQuery) params(from, to string) (url.Values, error) {
 	params := url.Values{}
 	if err := addProjectID(params); err != nil {
-		return err
+		return nil, err
+	}
+	if len(q.steps) > 0 {
+		events, err := funnelStepsParam(q.steps)
+		if err != nil {
+			return nil, err
+		}
+		params.Set("events", events)
+	} else {
+		params.Set("funnel_id", fmt.Sprintf("%d", q.funnelID))
 	}
-	params.Set("funnel_id", fmt.Sprintf("%d", funnelID))
 	params.Set("from_date", from)
 	params.Set("to_date", to)
 
-	if length > 0 {
-		params.Set("length", fmt.Sprintf("%d", length))
+	if q.length > 0 {
+		params.Set("length", fmt.Sprintf("%d", q.length))
 	}
-	if lengthUnit != "" {
-		params.Set("length_unit", lengthUnit)
+	if q.lengthUnit != "" {
+		params.Set("length_unit", q.lengthUnit)
 	}
-	if unit != "" {
-		params.Set("unit", unit)
+	if q.unit != "" {
+		params.Set("unit", q.unit)
 	}
-	if on != "" {
-		params.Set("on", on)
+	if q.on != "" {
+		params.Set("on", q.on)
 	}
-	if where != "" {
-		params.Set("where", where)
+	if q.where != "" {
+		params.Set("where", q.where)
 	}
-	if limit > 0 {
-		params.Set("limit", fmt.Sprintf("%d", limit))
+	if q.limit > 0 {
+		params.Set("limit", fmt.Sprintf("%d", q.limit))
+	}
+	return params, nil
+}
+
+// minFunnelSteps is the fewest steps an ad hoc funnel may have.
+const minFunnelSteps = 2
+
+// parseFunnelSteps returns the ad hoc funnel steps given by --steps, a
+// ";"-separated list, or by repeated --step flags. It returns nil when
+// neither is set.
+func parseFunnelSteps(list string, stepFlags []string) ([]string, error) {
+	raw := stepFlags
+	flag := "step"
+	if list != "" {
+		raw = strings.Split(list, ";")
+		flag = "steps"
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	steps := make([]string, len(raw))
+	for i, name := range raw {
+		steps[i] = strings.TrimSpace(name)
+		if steps[i] == "" {
+			return nil, fmt.Errorf("invalid `--%s`: step %d has no event name", flag, i+1)
+		}
+	}
+	if len(steps) < minFunnelSteps {
+		return nil, fmt.Errorf("an ad hoc funnel needs at least %d steps; got %d", minFunnelSteps, len(steps))
+	}
+	return steps, nil
+}
+
+// funnelStepsParam encodes ad hoc funnel steps as the /funnels events
+// parameter: a JSON array of {"event": name} objects, one per step in order.
+func funnelStepsParam(steps []string) (string, error) {
+	events := make([]map[string]string, len(steps))
+	for i, name := range steps {
+		events[i] = map[string]string{"event": name}
+	}
+	data, err := json.Marshal(events)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// fetch runs the funnel query over the range from-to.
+func (q funnelQuery) fetch(c *client.Client, from, to string) (map[string]any, error) {
+	params, err := q.params(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyExtraParams(params); err != nil {
+		return nil, err
 	}
 
 	resp, err := c.Get(client.APIFamilyQuery, "/funnels", params)
 	if err != nil {
-		return fmt.Errorf("querying funnels: %w", err)
+		return nil, fmt.Errorf("querying funnels: %w", err)
 	}
 
 	body, err := readResponseBody(resp.Body, resp.StatusCode)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var result map[string]any
 	if err := json.Unmarshal(body, &result); err != nil {
-		return fmt.Errorf("parsing funnels response: %w", err)
+		return nil, fmt.Errorf("parsing funnels response: %w", err)
+	}
+	if err := checkAPIError(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func runFunnelsQuery(cmd *cobra.Command, q funnelQuery, from, to string, opts funnelTableOptions) error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	result, err := q.fetch(c, from, to)
+	if err != nil {
+		return err
+	}
+
+	if opts.allDates {
+		return writeFunnelStepsJSONL(result)
 	}
 
 	handled, err := handleJSONOutput(cmd, result)
@@ -140,58 +311,209 @@ func runFunnelsQuery(cmd *cobra.Command, funnelID int, from, to string, length i
 		return nil
 	}
 
-	return renderFunnelTable(result)
+	if opts.trends {
+		return renderFunnelTrends(result)
+	}
+	return renderFunnelTable(result, opts.date)
 }
 
-// renderFunnelTable renders funnel step data as a table showing step name,
-// count, overall conversion %, and step conversion %.
-func renderFunnelTable(result map[string]any) error {
+// runFunnelsCompare runs q over the ranges from-to and cmpFrom-cmpTo and
+// renders their step totals side by side.
+func runFunnelsCompare(cmd *cobra.Command, q funnelQuery, from, to, cmpFrom, cmpTo string) error {
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	ranges := [][2]string{{from, to}, {cmpFrom, cmpTo}}
+	results := make([]map[string]any, len(ranges))
+	err = forEachConcurrent(len(ranges), func(i int) error {
+		var err error
+		results[i], err = q.fetch(c, ranges[i][0], ranges[i][1])
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	handled, err := handleJSONOutput(cmd, map[string]any{
+		"current": map[string]any{"from": from, "to": to, "result": results[0]},
+		"compare": map[string]any{"from": cmpFrom, "to": cmpTo, "result": results[1]},
+	})
+	if err != nil {
+		return err
+	}
+	if handled {
+		return nil
+	}
+
+	s := getIO()
+	current, previous := sumFunnelSteps(results[0]), sumFunnelSteps(results[1])
+	if len(current) == 0 && len(previous) == 0 {
+		return emptyResult(s, "No funnel data found.")
+	}
+
+	headers := []string{"STEP", "EVENT", "COUNT", "COMPARE COUNT", "CHANGE", "OVERALL %", "COMPARE OVERALL %", "CHANGE (PTS)"}
+	rows := make([][]string, 0, max(len(current), len(previous)))
+	for i := range max(len(current), len(previous)) {
+		var cur, prev funnelStepTotal
+		if i < len(current) {
+			cur = current[i]
+		}
+		if i < len(previous) {
+			prev = previous[i]
+		}
+		event := cur.event
+		if event == "" {
+			event = prev.event
+		}
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", i+1),
+			event,
+			output.FormatNumber(cur.count),
+			output.FormatNumber(prev.count),
+			formatChange(cur.count, prev.count),
+			fmt.Sprintf("%.1f%%", cur.overall*100),
+			fmt.Sprintf("%.1f%%", prev.overall*100),
+			fmt.Sprintf("%+.1f", (cur.overall-prev.overall)*100),
+		})
+	}
+
+	s.Printf("Funnel data for %s to %s compared with %s to %s:\n\n", from, to, cmpFrom, cmpTo)
+	output.PrintTable(s.Out, headers, rows, s.IsTerminal())
+	return nil
+}
+
+// funnelStepTotal is one funnel step summed over every date of a response.
+type funnelStepTotal struct {
+	event   string
+	count   float64
+	overall float64 // count as a fraction of the first step's count
+}
+
+// sumFunnelSteps sums each step's count over all dates in a funnel response
+// and derives the overall conversion from the totals.
+// Response shape: {"data": {date: {"steps": [{"event": "...", "count": 10}, ...]}}}
+func sumFunnelSteps(result map[string]any) []funnelStepTotal {
+	data, _ := result["data"].(map[string]any)
+
+	var totals []funnelStepTotal
+	for _, date := range funnelDates(result, data) {
+		dateData, _ := data[date].(map[string]any)
+		steps, _ := dateData["steps"].([]any)
+		for i, stepRaw := range steps {
+			step, _ := stepRaw.(map[string]any)
+			if i == len(totals) {
+				event, _ := step["event"].(string)
+				totals = append(totals, funnelStepTotal{event: event})
+			}
+			if n, ok := output.NumericValue(step["count"]); ok {
+				totals[i].count += n
+			}
+		}
+	}
+
+	if len(totals) > 0 && totals[0].count > 0 {
+		for i := range totals {
+			totals[i].overall = totals[i].count / totals[0].count
+		}
+	}
+	return totals
+}
+
+// funnelTableOptions controls how funnel query results are rendered as a table.
+type funnelTableOptions struct {
+	// trends renders overall conversion per date instead of the steps of a
+	// single date.
+	trends bool
+	// date selects which date's steps to render; empty means the latest.
+	date string
+	// allDates writes every date's steps as JSON Lines instead of a table.
+	allDates bool
+}
+
+// writeFunnelStepsJSONL writes one JSON object per date and step across the
+// whole data map, in date order.
+func writeFunnelStepsJSONL(result map[string]any) error {
 	s := getIO()
 
-	// The response has {"data": {date: {"steps": [...]}}, "meta": {"dates": [...]}}
 	data, ok := result["data"].(map[string]any)
 	if !ok {
 		return output.PrintJSON(s.Out, result)
 	}
 
-	// Find the latest date's steps to show the overall funnel.
-	meta, _ := result["meta"].(map[string]any)
-	dates, _ := meta["dates"].([]any)
-
-	if len(dates) == 0 {
-		// Try using data keys directly.
-		for k := range data {
-			dates = append(dates, k)
+	jw := output.NewJSONLWriter(s.Out)
+	written := 0
+	for _, date := range funnelDates(result, data) {
+		dateData, ok := data[date].(map[string]any)
+		if !ok {
+			continue
+		}
+		steps, _ := dateData["steps"].([]any)
+		for i, stepRaw := range steps {
+			step, ok := stepRaw.(map[string]any)
+			if !ok {
+				continue
+			}
+			if err := jw.Write(map[string]any{
+				"date":               date,
+				"step":               i + 1,
+				"event":              step["event"],
+				"count":              step["count"],
+				"overall_conv_ratio": step["overall_conv_ratio"],
+				"step_conv_ratio":    step["step_conv_ratio"],
+			}); err != nil {
+				return err
+			}
+			written++
 		}
 	}
-	if len(dates) == 0 {
-		s.Printf("No data returned.\n")
-		return nil
+	if written == 0 {
+		return emptyResult(s, "No funnel data found.")
 	}
+	return nil
+}
+
+// renderFunnelTable renders funnel step data as a table showing step name,
+// count, overall conversion %, and step conversion %. It shows the steps for
+// date, or for the latest date with data when date is empty.
+func renderFunnelTable(result map[string]any, date string) error {
+	s := getIO()
 
-	// Use the last date.
-	latestDate := fmt.Sprintf("%v", dates[len(dates)-1])
-	dateData, ok := data[latestDate].(map[string]any)
+	// The response has {"data": {date: {"steps": [...]}}, "meta": {"dates": [...]}}
+	data, ok := result["data"].(map[string]any)
 	if !ok {
-		// Try to find any date with data.
-		for _, d := range dates {
-			ds := fmt.Sprintf("%v", d)
-			if dd, ok2 := data[ds].(map[string]any); ok2 {
+		return output.PrintJSON(s.Out, result)
+	}
+
+	dates := funnelDates(result, data)
+	if len(dates) == 0 {
+		return emptyResult(s, "No data returned.")
+	}
+
+	var dateData map[string]any
+	if date != "" {
+		dateData, ok = data[date].(map[string]any)
+		if !ok {
+			return fmt.Errorf("no funnel data for %s; available dates: %s", date, strings.Join(funnelDatesWithData(dates, data), ", "))
+		}
+	} else {
+		// Use the latest date that has data.
+		for i := len(dates) - 1; i >= 0; i-- {
+			if dd, ok := data[dates[i]].(map[string]any); ok {
 				dateData = dd
-				latestDate = ds
+				date = dates[i]
 				break
 			}
 		}
 		if dateData == nil {
-			s.Printf("No funnel data found.\n")
-			return nil
+			return emptyResult(s, "No funnel data found.")
 		}
 	}
 
 	steps, ok := dateData["steps"].([]any)
 	if !ok || len(steps) == 0 {
-		s.Printf("No funnel steps found.\n")
-		return nil
+		return emptyResult(s, "No funnel steps found.")
 	}
 
 	headers := []string{"STEP", "EVENT", "COUNT", "OVERALL %", "STEP %"}
@@ -218,11 +540,88 @@ func renderFunnelTable(result map[string]any) error {
 		rows = append(rows, row)
 	}
 
-	s.Printf("Funnel data for %s:\n\n", latestDate)
+	s.Printf("Funnel data for %s:\n\n", date)
+	output.PrintTable(s.Out, headers, rows, s.IsTerminal())
+	return nil
+}
+
+// renderFunnelTrends renders one row per date with the number of users who
+// entered the funnel, the number who completed it, and the overall conversion.
+func renderFunnelTrends(result map[string]any) error {
+	s := getIO()
+
+	data, ok := result["data"].(map[string]any)
+	if !ok {
+		return output.PrintJSON(s.Out, result)
+	}
+
+	dates := funnelDates(result, data)
+
+	headers := []string{"DATE", "STARTED", "COMPLETED", "CONVERSION %"}
+	rows := make([][]string, 0, len(dates))
+	for _, date := range dates {
+		dateData, ok := data[date].(map[string]any)
+		if !ok {
+			continue
+		}
+		steps, _ := dateData["steps"].([]any)
+		if len(steps) == 0 {
+			continue
+		}
+		first, _ := steps[0].(map[string]any)
+		last, _ := steps[len(steps)-1].(map[string]any)
+
+		started, _ := first["count"].(float64)
+		completed, _ := last["count"].(float64)
+		conv, _ := last["overall_conv_ratio"].(float64)
+
+		rows = append(rows, []string{
+			date,
+			fmt.Sprintf("%.0f", started),
+			fmt.Sprintf("%.0f", completed),
+			fmt.Sprintf("%.1f%%", conv*100),
+		})
+	}
+
+	if len(rows) == 0 {
+		return emptyResult(s, "No funnel data found.")
+	}
+
 	output.PrintTable(s.Out, headers, rows, s.IsTerminal())
 	return nil
 }
 
+// funnelDates returns the dates of a funnel response in ascending order, from
+// meta.dates when present and otherwise from the keys of data. Either source
+// is sorted, since neither order is guaranteed, so the last date is always
+// the latest.
+func funnelDates(result, data map[string]any) []string {
+	meta, _ := result["meta"].(map[string]any)
+	datesRaw, _ := meta["dates"].([]any)
+	dates := make([]string, 0, len(data))
+	for _, d := range datesRaw {
+		dates = append(dates, fmt.Sprintf("%v", d))
+	}
+	if len(dates) == 0 {
+		for k := range data {
+			dates = append(dates, k)
+		}
+	}
+	sort.Strings(dates)
+	return dates
+}
+
+// funnelDatesWithData filters dates to those that have an entry in data.
+func funnelDatesWithData(dates []string, data map[string]any) []string {
+	available := make([]string, 0, len(dates))
+	for _, d := range dates {
+		if _, ok := data[d].(map[string]any); ok {
+			available = append(available, d)
+		}
+	}
+	return available
+}
+
 func newFunnelsListCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "list",
@@ -237,52 +636,64 @@ func newFunnelsListCmd() *cobra.Command {
 			return runFunnelsList(cmd)
 		},
 	}
+	setRequestTimeout(cmd, metadataRequestTimeout)
+
 	return cmd
 }
 
 func runFunnelsList(cmd *cobra.Command) error {
-	c, err := newClient()
+	funnels, err := fetchFunnels()
 	if err != nil {
 		return err
 	}
 
-	params := url.Values{}
-	if err := addProjectID(params); err != nil {
+	handled, err := handleJSONOutput(cmd, funnels)
+	if err != nil {
 		return err
 	}
-
-	resp, err := c.Get(client.APIFamilyQuery, "/funnels/list", params)
-	if err != nil {
-		return fmt.Errorf("listing funnels: %w", err)
+	if handled {
+		return nil
 	}
 
-	body, err := readResponseBody(resp.Body, resp.StatusCode)
+	return renderFunnelsList(funnels)
+}
+
+// fetchFunnels returns the saved funnels in the current project.
+// Response shape: [{"funnel_id": 7509, "name": "..."}]
+func fetchFunnels() ([]map[string]any, error) {
+	c, err := newClient()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	var funnels []map[string]any
-	if err := json.Unmarshal(body, &funnels); err != nil {
-		return fmt.Errorf("parsing funnels list response: %w", err)
+	params := url.Values{}
+	if err := addProjectID(params); err != nil {
+		return nil, err
 	}
 
-	handled, err := handleJSONOutput(cmd, funnels)
+	body, err := cachedResponse("/funnels/list", params, func(h http.Header) (*http.Response, error) {
+		resp, err := c.GetWithHeader(client.APIFamilyQuery, "/funnels/list", params, h)
+		if err != nil {
+			return nil, fmt.Errorf("listing funnels: %w", err)
+		}
+		return resp, nil
+	})
 	if err != nil {
-		return err
-	}
-	if handled {
-		return nil
+		return nil, err
 	}
 
-	return renderFunnelsList(funnels)
+	var funnels []map[string]any
+	if err := json.Unmarshal(body, &funnels); err != nil {
+		return nil, fmt.Errorf("parsing funnels list response: %w", err)
+	}
+	return funnels, nil
 }
 
 func renderFunnelsList(funnels []map[string]any) error {
 	s := getIO()
 
 	if len(funnels) == 0 {
-		s.Printf("No funnels found.\n")
-		return nil
+		return emptyResult(s, "No funnels found.")
 	}
 
 	// Sort by funnel_id for consistent output.
@@ -302,5 +713,6 @@ func renderFunnelsList(funnels []map[string]any) error {
 	}
 
 	output.PrintTable(s.Out, headers, rows, s.IsTerminal())
+	s.Footer("%s", pluralize(len(rows), "funnel"))
 	return nil
 }
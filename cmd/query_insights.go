@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/url"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/aviadshiber/mp/internal/client"
 	"github.com/aviadshiber/mp/internal/output"
@@ -16,44 +18,100 @@ func init() {
 }
 
 func newQueryInsightsCmd() *cobra.Command {
-	var bookmarkID int
+	var (
+		bookmarkID int
+		name       string
+		rawSeries  bool
+		from       string
+		to         string
+	)
 
 	cmd := &cobra.Command{
 		Use:   "insights",
 		Short: "Query a saved Insights report",
-		Long: `Query a saved Insights report by its bookmark ID. Returns the computed series
-data for the report.`,
+		Long: `Query a saved Insights report by its bookmark ID or name. Returns the computed
+series data for the report.
+
+--name looks the report up among the project's saved Insights reports
+(case-insensitive exact match) and fails if no report or more than one report
+has that name, listing the candidates.
+
+The table flattens the series into one column per series. Use --raw-series to
+write every data point as a JSON Lines object instead:
+
+  {"series_name": "Signup / US", "date": "2024-01-01", "value": 42}
+
+Nested breakdown names are joined with " / " in series_name.
+
+--from and --to narrow the table to a date range. The /insights endpoint
+only computes a saved report over its own date range and unit and has no
+parameters to override them, so mp filters the table rows client-side: the
+range can be narrowed but not extended, and mp warns when the requested range
+reaches outside the report's. --json and --raw-series return the report as the
+API computed it. There is no --unit; to change the unit, or to cover other
+dates, edit the saved report in Mixpanel.`,
 		Example: `  # Query a saved insight
   mp query insights --bookmark-id 12345
 
+  # Query a saved insight by name
+  mp query insights --name "Weekly signups"
+
+  # Only the first week of the saved report's range
+  mp query insights --bookmark-id 12345 --from 2024-01-01 --to 2024-01-07
+
   # JSON output
   mp query insights --bookmark-id 12345 --json
 
+  # Every data point as JSON Lines
+  mp query insights --bookmark-id 12345 --raw-series
+
   # Filter with jq
-  mp query insights --bookmark-id 12345 --json --jq '.series'`,
+  mp query insights --bookmark-id 12345 --jq '.series'`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runQueryInsights(cmd, bookmarkID)
+			if rawSeries && jsonOutputRequested(cmd) {
+				return fmt.Errorf("`--raw-series` and `--json` cannot be used together")
+			}
+			if (bookmarkID == 0) == (name == "") {
+				return fmt.Errorf("exactly one of `--bookmark-id` or `--name` is required")
+			}
+			return runQueryInsights(cmd, bookmarkID, name, rawSeries, from, to)
 		},
 	}
 
-	cmd.Flags().IntVar(&bookmarkID, "bookmark-id", 0, "Saved report bookmark ID (required)")
-	_ = cmd.MarkFlagRequired("bookmark-id")
+	cmd.Flags().IntVar(&bookmarkID, "bookmark-id", 0, "Saved report bookmark ID")
+	cmd.Flags().StringVar(&name, "name", "", "Saved report name (alternative to --bookmark-id)")
+	cmd.Flags().BoolVar(&rawSeries, "raw-series", false, "Write each series data point as JSON Lines")
+	addDateRangeFlags(cmd, &from, &to, false)
+	cmd.Flags().Lookup("from").Usage = "Only show dates from yyyy-mm-dd on, within the saved report's range (table output only; alias: --since)"
+	cmd.Flags().Lookup("to").Usage = "Only show dates up to yyyy-mm-dd, within the saved report's range (table output only; alias: --until)"
 
+	addExtraParamsFlag(cmd)
 	return cmd
 }
 
-func runQueryInsights(cmd *cobra.Command, bookmarkID int) error {
+func runQueryInsights(cmd *cobra.Command, bookmarkID int, name string, rawSeries bool, from, to string) error {
 	c, err := newClient()
 	if err != nil {
 		return err
 	}
 
+	if name != "" {
+		bookmarkID, err = findInsightsBookmark(c, name)
+		if err != nil {
+			return err
+		}
+	}
+
 	params := url.Values{}
 	if err := addProjectID(params); err != nil {
 		return err
 	}
 	params.Set("bookmark_id", fmt.Sprintf("%d", bookmarkID))
 
+	if err := applyExtraParams(params); err != nil {
+		return err
+	}
+
 	resp, err := c.Get(client.APIFamilyQuery, "/insights", params)
 	if err != nil {
 		return fmt.Errorf("querying insights: %w", err)
@@ -68,6 +126,12 @@ func runQueryInsights(cmd *cobra.Command, bookmarkID int) error {
 	if err := json.Unmarshal(body, &result); err != nil {
 		return fmt.Errorf("parsing insights response: %w", err)
 	}
+	if err := checkAPIError(result); err != nil {
+		return err
+	}
+	if rawSeries {
+		return writeInsightsSeriesJSONL(result)
+	}
 
 	handled, err := handleJSONOutput(cmd, result)
 	if err != nil {
@@ -77,9 +141,118 @@ func runQueryInsights(cmd *cobra.Command, bookmarkID int) error {
 		return nil
 	}
 
+	if from != "" || to != "" {
+		narrowInsightsRange(result, from, to)
+	}
 	return renderInsightsTable(result)
 }
 
+// findInsightsBookmark returns the bookmark ID of the saved Insights report
+// named name, matched case-insensitively.
+// Response shape: {"results": [{"id": 123, "name": "...", "type": "insights"}, ...]}
+func findInsightsBookmark(c *client.Client, name string) (int, error) {
+	pid, err := requireProjectID()
+	if err != nil {
+		return 0, err
+	}
+
+	params := url.Values{}
+	params.Set("type", "insights")
+
+	path := fmt.Sprintf("/projects/%s/bookmarks", pid)
+	resp, err := c.Get(client.APIFamilyApp, path, params)
+	if err != nil {
+		return 0, fmt.Errorf("listing insights reports: %w", err)
+	}
+
+	body, err := readResponseBody(resp.Body, resp.StatusCode)
+	if err != nil {
+		return 0, err
+	}
+
+	var result struct {
+		Results []struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return 0, fmt.Errorf("parsing insights reports response: %w", err)
+	}
+
+	var matches []string
+	id := 0
+	for _, r := range result.Results {
+		if strings.EqualFold(r.Name, name) {
+			id = r.ID
+			matches = append(matches, fmt.Sprintf("%d (%s)", r.ID, r.Name))
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return 0, fmt.Errorf("no Insights report named %q", name)
+	case 1:
+		return id, nil
+	default:
+		return 0, fmt.Errorf("%d Insights reports are named %q; use `--bookmark-id` with one of: %s", len(matches), name, strings.Join(matches, ", "))
+	}
+}
+
+// narrowInsightsRange drops the data points of result outside the inclusive
+// range from-to, either of which may be empty for an open end. Series and
+// header keys that are not dates are kept. It warns when the range reaches
+// outside the dates the saved report returned, since those cannot be added.
+func narrowInsightsRange(result map[string]any, from, to string) {
+	var first, last string
+	inRange := func(key string) (isDate, keep bool) {
+		if len(key) < len(dateLayout) {
+			return false, true
+		}
+		day := key[:len(dateLayout)]
+		if _, err := time.Parse(dateLayout, day); err != nil {
+			return false, true
+		}
+		if first == "" || day < first {
+			first = day
+		}
+		if day > last {
+			last = day
+		}
+		return true, (from == "" || day >= from) && (to == "" || day <= to)
+	}
+
+	var walk func(node map[string]any)
+	walk = func(node map[string]any) {
+		for k, v := range node {
+			if child, ok := v.(map[string]any); ok {
+				walk(child)
+				continue
+			}
+			if _, keep := inRange(k); !keep {
+				delete(node, k)
+			}
+		}
+	}
+	if series, ok := result["series"].(map[string]any); ok {
+		walk(series)
+	}
+
+	if headers, ok := result["headers"].([]any); ok {
+		kept := make([]any, 0, len(headers))
+		for _, h := range headers {
+			if _, keep := inRange(fmt.Sprintf("%v", h)); keep {
+				kept = append(kept, h)
+			}
+		}
+		result["headers"] = kept
+	}
+
+	if first != "" && ((from != "" && from < first) || (to != "" && to > last)) {
+		s := getIO()
+		s.Errorf("%s the saved report covers %s to %s; `--from`/`--to` can only narrow it\n", s.Warning("warning:"), first, last)
+	}
+}
+
 // renderInsightsTable renders insights data as a table.
 // Response shape: {"series": {eventName: {date: count}}, "headers": [...dates], ...}
 func renderInsightsTable(result map[string]any) error {
@@ -116,31 +289,60 @@ func renderInsightsTable(result map[string]any) error {
 	}
 
 	if len(dates) == 0 || len(eventNames) == 0 {
-		s.Printf("No insights data returned.\n")
-		return nil
+		return emptyResult(s, "No insights data returned.")
+	}
+
+	output.RenderMap2D(s.Out, dates, series, output.Map2DOptions{Order: eventNames}, s.IsTerminal())
+	return nil
+}
+
+// insightsSeriesSep joins nested breakdown names in --raw-series output.
+const insightsSeriesSep = " / "
+
+// writeInsightsSeriesJSONL writes one {series_name, date, value} object per
+// data point. Series may nest breakdown maps to any depth; the innermost map
+// is keyed by date.
+func writeInsightsSeriesJSONL(result map[string]any) error {
+	s := getIO()
+
+	series, ok := result["series"].(map[string]any)
+	if !ok {
+		return output.PrintJSON(s.Out, result)
 	}
 
-	// Build headers: DATE + one column per event.
-	headers := make([]string, 0, 1+len(eventNames))
-	headers = append(headers, "DATE")
-	headers = append(headers, eventNames...)
-
-	rows := make([][]string, 0, len(dates))
-	for _, date := range dates {
-		row := make([]string, 0, 1+len(eventNames))
-		row = append(row, date)
-		for _, name := range eventNames {
-			val := "0"
-			if evData, ok := series[name].(map[string]any); ok {
-				if v, exists := evData[date]; exists {
-					val = fmt.Sprintf("%v", v)
+	jw := output.NewJSONLWriter(s.Out)
+	written := 0
+	var walk func(path []string, node map[string]any) error
+	walk = func(path []string, node map[string]any) error {
+		keys := make([]string, 0, len(node))
+		for k := range node {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			if child, ok := node[k].(map[string]any); ok {
+				if err := walk(append(path, k), child); err != nil {
+					return err
 				}
+				continue
+			}
+			if err := jw.Write(map[string]any{
+				"series_name": strings.Join(path, insightsSeriesSep),
+				"date":        k,
+				"value":       node[k],
+			}); err != nil {
+				return err
 			}
-			row = append(row, val)
+			written++
 		}
-		rows = append(rows, row)
+		return nil
+	}
+	if err := walk(nil, series); err != nil {
+		return err
+	}
+	if written == 0 {
+		return emptyResult(s, "No insights data returned.")
 	}
-
-	output.PrintTable(s.Out, headers, rows, s.IsTerminal())
 	return nil
 }
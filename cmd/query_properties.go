@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"sort"
 
 	"github.com/aviadshiber/mp/internal/client"
+	"github.com/aviadshiber/mp/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -16,13 +18,11 @@ func init() {
 func newQueryPropertiesCmd() *cobra.Command {
 	var (
 		event     string
-		from      string
-		to        string
-		on        string
-		where     string
 		queryType string
-		unit      string
-		limit     int
+		percent   bool
+		transpose bool
+		top       bool
+		qf        *queryFlags
 	)
 
 	cmd := &cobra.Command{
@@ -30,7 +30,11 @@ func newQueryPropertiesCmd() *cobra.Command {
 		Short: "Query event properties over time",
 		Long: `Query event property data from the Mixpanel analytics API. Returns property
 values broken down by time, similar to segmentation but scoped to a single
-event's properties.`,
+event's properties.
+
+--top replaces the time grid with a VALUE/TOTAL table ranking each property
+value by its total over the whole range, highest first. --limit caps both the
+values the API returns and the rows shown. --json output is unchanged.`,
 		Example: `  # Daily breakdown of Signup by country
   mp query properties --event "Signup" --from 2024-01-01 --to 2024-01-31 \
     --on 'properties["country"]'
@@ -43,31 +47,45 @@ event's properties.`,
   mp query properties --event "Page View" --from 2024-01-01 --to 2024-01-31 \
     --on 'properties["page"]' --where 'properties["country"]=="US"' --limit 50
 
+  # Most common countries over the month, ranked
+  mp query properties --event "Signup" --from 2024-01-01 --to 2024-01-31 \
+    --on 'properties["country"]' --top --limit 10
+
   # JSON output with jq
   mp query properties --event "Signup" --from 2024-01-01 --to 2024-01-31 \
-    --on 'properties["country"]' --json --jq '.data.values'`,
+    --on 'properties["country"]' --jq '.data.values'`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runQueryProperties(cmd, event, from, to, on, where, queryType, unit, limit)
+			if top && (percent || transpose) {
+				return fmt.Errorf("`--top` cannot be combined with `--percent` or `--transpose`")
+			}
+			if err := validateLimit(qf.limit, maxPropertiesLimit); err != nil {
+				return err
+			}
+			opts := segmentationTableOptions{percent: percent, transpose: transpose}
+			return runQueryProperties(cmd, event, qf.from, qf.to, qf.on, qf.where, queryType, qf.unit, qf.limit, top, opts)
 		},
 	}
 
 	cmd.Flags().StringVar(&event, "event", "", "Event name (required)")
-	cmd.Flags().StringVar(&from, "from", "", "Start date yyyy-mm-dd (required)")
-	cmd.Flags().StringVar(&to, "to", "", "End date yyyy-mm-dd (required)")
-	cmd.Flags().StringVar(&on, "on", "", "Property expression for breakdown (e.g., properties[\"country\"])")
-	cmd.Flags().StringVar(&where, "where", "", "Filter expression")
+	qf = addCommonQueryFlags(cmd, queryFlagSpec{
+		units:    []string{"minute", "hour", "day", "week", "month"},
+		on:       breakdownHelp,
+		where:    filterHelp,
+		limit:    "property values",
+		maxLimit: maxPropertiesLimit,
+	})
 	cmd.Flags().StringVar(&queryType, "type", "", "Aggregation type: general, unique, average")
-	cmd.Flags().StringVar(&unit, "unit", "", "Time unit: minute, hour, day, week, month")
-	cmd.Flags().IntVar(&limit, "limit", 0, "Maximum number of property values (max 10000)")
+	cmd.Flags().BoolVar(&percent, "percent", false, "Show each value as a percentage of the date's total")
+	cmd.Flags().BoolVar(&transpose, "transpose", false, "Swap the rows and columns of the table")
+	cmd.Flags().BoolVar(&top, "top", false, "Rank property values by their total over the range instead of showing a time grid")
 
-	_ = cmd.MarkFlagRequired("event")
-	_ = cmd.MarkFlagRequired("from")
-	_ = cmd.MarkFlagRequired("to")
+	addEventPicker(cmd, &event)
 
+	addExtraParamsFlag(cmd)
 	return cmd
 }
 
-func runQueryProperties(cmd *cobra.Command, event, from, to, on, where, queryType, unit string, limit int) error {
+func runQueryProperties(cmd *cobra.Command, event, from, to, on, where, queryType, unit string, limit int, top bool, opts segmentationTableOptions) error {
 	c, err := newClient()
 	if err != nil {
 		return err
@@ -97,6 +115,10 @@ func runQueryProperties(cmd *cobra.Command, event, from, to, on, where, queryTyp
 		params.Set("limit", fmt.Sprintf("%d", limit))
 	}
 
+	if err := applyExtraParams(params); err != nil {
+		return err
+	}
+
 	resp, err := c.Get(client.APIFamilyQuery, "/events/properties", params)
 	if err != nil {
 		return fmt.Errorf("querying event properties: %w", err)
@@ -111,6 +133,9 @@ func runQueryProperties(cmd *cobra.Command, event, from, to, on, where, queryTyp
 	if err := json.Unmarshal(body, &result); err != nil {
 		return fmt.Errorf("parsing properties response: %w", err)
 	}
+	if err := checkAPIError(result); err != nil {
+		return err
+	}
 
 	handled, err := handleJSONOutput(cmd, result)
 	if err != nil {
@@ -120,6 +145,65 @@ func runQueryProperties(cmd *cobra.Command, event, from, to, on, where, queryTyp
 		return nil
 	}
 
+	if top {
+		return renderPropertyRanking(result, limit)
+	}
+
 	// Reuse the segmentation table renderer since the response shape is identical.
-	return renderSegmentationTable(result)
+	return renderSegmentationTable(result, opts)
+}
+
+// propertyTotal is a property value and its total over the queried range.
+type propertyTotal struct {
+	value string
+	total float64
+}
+
+// rankPropertyValues sums each property value's numeric counts across all
+// dates and returns the values ordered by total, highest first, with ties
+// ordered by value. A positive limit keeps only the first limit values.
+// Response shape: {"data": {"values": {"US": {"2024-01-01": 10, ...}, ...}}}
+func rankPropertyValues(result map[string]any, limit int) []propertyTotal {
+	data, _ := result["data"].(map[string]any)
+	values, _ := data["values"].(map[string]any)
+
+	ranked := make([]propertyTotal, 0, len(values))
+	for value, byDate := range values {
+		dates, _ := byDate.(map[string]any)
+		total := 0.0
+		for _, v := range dates {
+			if n, ok := output.NumericValue(v); ok {
+				total += n
+			}
+		}
+		ranked = append(ranked, propertyTotal{value: value, total: total})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].total != ranked[j].total {
+			return ranked[i].total > ranked[j].total
+		}
+		return ranked[i].value < ranked[j].value
+	})
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+// renderPropertyRanking renders property values ranked by total as a
+// VALUE/TOTAL table.
+func renderPropertyRanking(result map[string]any, limit int) error {
+	s := getIO()
+
+	ranked := rankPropertyValues(result, limit)
+	if len(ranked) == 0 {
+		return emptyResult(s, "No data returned.")
+	}
+
+	rows := make([][]string, 0, len(ranked))
+	for _, r := range ranked {
+		rows = append(rows, []string{r.value, output.FormatNumber(r.total)})
+	}
+	output.PrintTable(s.Out, []string{"VALUE", "TOTAL"}, rows, s.IsTerminal())
+	return nil
 }
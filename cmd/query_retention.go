@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"sort"
+	"strings"
 
 	"github.com/aviadshiber/mp/internal/client"
 	"github.com/aviadshiber/mp/internal/output"
@@ -17,31 +18,40 @@ func init() {
 
 func newQueryRetentionCmd() *cobra.Command {
 	var (
-		from          string
-		to            string
 		retentionType string
 		bornEvent     string
 		event         string
 		bornWhere     string
-		where         string
 		interval      int
 		intervalCount int
-		unit          string
-		on            string
-		limit         int
+		jsonMatrix    bool
+		qf            *queryFlags
 	)
 
 	cmd := &cobra.Command{
 		Use:   "retention",
 		Short: "Query user retention data",
 		Long: `Query user retention data from the Mixpanel analytics API. Shows how many users
-return to perform an action after their initial visit or signup.`,
+return to perform an action after their initial visit or signup.
+
+Birth retention measures users from a birth event: --born-event is required
+with --retention-type birth, and setting --born-event or --born-where selects
+birth retention when --retention-type is omitted. Combining them with
+--retention-type compounded is an error.
+
+--json-matrix writes the retention grid in long format as JSON Lines, one
+record per cohort date and period, which loads directly into a DataFrame:
+
+  {"date": "2024-01-01", "first": 100, "period": 0, "count": 100}
+
+Every date gets a record for each period of the longest cohort; periods that
+have not elapsed yet for recent cohorts have a null count.`,
 		Example: `  # Basic retention for January 2024
   mp query retention --from 2024-01-01 --to 2024-01-31
 
-  # Birth retention based on Signup event
+  # Birth retention based on Signup event (--retention-type birth is implied)
   mp query retention --from 2024-01-01 --to 2024-01-31 \
-    --retention-type birth --born-event "Signup" --event "Login"
+    --born-event "Signup" --event "Login"
 
   # Weekly retention with property breakdown
   mp query retention --from 2024-01-01 --to 2024-03-31 \
@@ -51,35 +61,66 @@ return to perform an action after their initial visit or signup.`,
   mp query retention --from 2024-01-01 --to 2024-01-31 \
     --interval 7 --interval-count 10
 
+  # Long-format records for pandas.read_json(..., lines=True)
+  mp query retention --from 2024-01-01 --to 2024-01-31 --json-matrix > retention.jsonl
+
   # JSON output
   mp query retention --from 2024-01-01 --to 2024-01-31 --json`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runQueryRetention(cmd, from, to, retentionType, bornEvent, event,
-				bornWhere, where, interval, intervalCount, unit, on, limit)
+			if jsonMatrix && jsonOutputRequested(cmd) {
+				return fmt.Errorf("`--json-matrix` cannot be combined with `--json`")
+			}
+			return runQueryRetention(cmd, qf.from, qf.to, retentionType, bornEvent, event,
+				bornWhere, qf.where, interval, intervalCount, qf.unit, qf.on, qf.limit, jsonMatrix)
 		},
 	}
 
-	cmd.Flags().StringVar(&from, "from", "", "Start date yyyy-mm-dd (required)")
-	cmd.Flags().StringVar(&to, "to", "", "End date yyyy-mm-dd (required)")
+	qf = addCommonQueryFlags(cmd, queryFlagSpec{
+		units: []string{"day", "week", "month"},
+		on:    breakdownHelp,
+		where: "Filter expression for return event",
+		limit: "breakdown values",
+	})
 	cmd.Flags().StringVar(&retentionType, "retention-type", "", "Retention type: birth, compounded")
 	cmd.Flags().StringVar(&bornEvent, "born-event", "", "Birth event name (for birth retention)")
 	cmd.Flags().StringVar(&event, "event", "", "Return event name")
 	cmd.Flags().StringVar(&bornWhere, "born-where", "", "Filter expression for birth event")
-	cmd.Flags().StringVar(&where, "where", "", "Filter expression for return event")
 	cmd.Flags().IntVar(&interval, "interval", 0, "Interval length in units")
 	cmd.Flags().IntVar(&intervalCount, "interval-count", 0, "Number of intervals to show")
-	cmd.Flags().StringVar(&unit, "unit", "", "Time unit: day, week, month")
-	cmd.Flags().StringVar(&on, "on", "", "Property expression for breakdown")
-	cmd.Flags().IntVar(&limit, "limit", 0, "Maximum number of breakdown values")
-
-	_ = cmd.MarkFlagRequired("from")
-	_ = cmd.MarkFlagRequired("to")
+	cmd.Flags().BoolVar(&jsonMatrix, "json-matrix", false, "Write one JSON line per date and period: {date, first, period, count}")
 
+	addExtraParamsFlag(cmd)
 	return cmd
 }
 
+// resolveRetentionType validates the birth retention flags and returns the
+// retention type to send. --born-event and --born-where imply "birth".
+func resolveRetentionType(retentionType, bornEvent, bornWhere string) (string, error) {
+	switch retentionType {
+	case "", "birth", "compounded":
+	default:
+		return "", fmt.Errorf("invalid `--retention-type` %q; must be one of: birth, compounded", retentionType)
+	}
+
+	if bornEvent != "" || bornWhere != "" {
+		if retentionType == "compounded" {
+			return "", fmt.Errorf("`--born-event` and `--born-where` only apply to `--retention-type birth`")
+		}
+		retentionType = "birth"
+	}
+	if retentionType == "birth" && bornEvent == "" {
+		return "", fmt.Errorf("`--retention-type birth` requires `--born-event`")
+	}
+	return retentionType, nil
+}
+
 func runQueryRetention(cmd *cobra.Command, from, to, retentionType, bornEvent, event,
-	bornWhere, where string, interval, intervalCount int, unit, on string, limit int) error {
+	bornWhere, where string, interval, intervalCount int, unit, on string, limit int, jsonMatrix bool) error {
+	retentionType, err := resolveRetentionType(retentionType, bornEvent, bornWhere)
+	if err != nil {
+		return err
+	}
+
 	c, err := newClient()
 	if err != nil {
 		return err
@@ -123,6 +164,10 @@ func runQueryRetention(cmd *cobra.Command, from, to, retentionType, bornEvent, e
 		params.Set("limit", fmt.Sprintf("%d", limit))
 	}
 
+	if err := applyExtraParams(params); err != nil {
+		return err
+	}
+
 	resp, err := c.Get(client.APIFamilyQuery, "/retention", params)
 	if err != nil {
 		return fmt.Errorf("querying retention: %w", err)
@@ -137,6 +182,13 @@ func runQueryRetention(cmd *cobra.Command, from, to, retentionType, bornEvent, e
 	if err := json.Unmarshal(body, &result); err != nil {
 		return fmt.Errorf("parsing retention response: %w", err)
 	}
+	if err := checkAPIError(result); err != nil {
+		return err
+	}
+
+	if jsonMatrix {
+		return writeRetentionMatrix(result)
+	}
 
 	handled, err := handleJSONOutput(cmd, result)
 	if err != nil {
@@ -146,45 +198,81 @@ func runQueryRetention(cmd *cobra.Command, from, to, retentionType, bornEvent, e
 		return nil
 	}
 
-	return renderRetentionTable(result)
+	return renderRetentionTable(result, unit)
 }
 
-// renderRetentionTable renders retention data as a table.
-// Response shape: {"2024-01-01": {"counts": [100, 50, 30], "first": 100}, ...}
-func renderRetentionTable(result map[string]any) error {
-	s := getIO()
-
-	if len(result) == 0 {
-		s.Printf("No retention data returned.\n")
-		return nil
-	}
-
-	// Collect and sort dates.
-	dates := make([]string, 0, len(result))
-	maxCols := 0
+// retentionGrid returns the sorted cohort dates of a retention response and
+// the number of periods of its longest cohort.
+func retentionGrid(result map[string]any) (dates []string, periods int) {
 	for date, v := range result {
-		if _, ok := v.(map[string]any); !ok {
+		entry, ok := v.(map[string]any)
+		if !ok {
 			continue
 		}
 		dates = append(dates, date)
-		if entry, ok := v.(map[string]any); ok {
-			if counts, ok := entry["counts"].([]any); ok && len(counts) > maxCols {
-				maxCols = len(counts)
-			}
+		if counts, ok := entry["counts"].([]any); ok && len(counts) > periods {
+			periods = len(counts)
 		}
 	}
 	sort.Strings(dates)
+	return dates, periods
+}
+
+// writeRetentionMatrix writes the retention grid as JSON Lines in long
+// format, one {date, first, period, count} record per date and period, so
+// there are len(dates) × periods records. Periods a cohort has not reached
+// yet have a null count.
+func writeRetentionMatrix(result map[string]any) error {
+	s := getIO()
 
+	dates, periods := retentionGrid(result)
 	if len(dates) == 0 {
-		s.Printf("No retention data returned.\n")
-		return nil
+		return emptyResult(s, "No retention data returned.")
+	}
+	jw := output.NewJSONLWriter(s.Out)
+	for _, date := range dates {
+		entry := result[date].(map[string]any)
+		counts, _ := entry["counts"].([]any)
+		for p := 0; p < periods; p++ {
+			var count any
+			if p < len(counts) {
+				count = counts[p]
+			}
+			if err := jw.Write(map[string]any{
+				"date":   date,
+				"first":  entry["first"],
+				"period": p,
+				"count":  count,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// renderRetentionTable renders retention data as a table. unit is the
+// requested time unit and determines the interval column labels.
+// Response shape: {"2024-01-01": {"counts": [100, 50, 30], "first": 100}, ...}
+func renderRetentionTable(result map[string]any, unit string) error {
+	s := getIO()
+
+	if len(result) == 0 {
+		return emptyResult(s, "No retention data returned.")
 	}
 
-	// Build headers: DATE | FIRST | DAY 0 | DAY 1 | ...
+	dates, maxCols := retentionGrid(result)
+
+	if len(dates) == 0 {
+		return emptyResult(s, "No retention data returned.")
+	}
+
+	// Build headers: DATE | FIRST | DAY 0 | DAY 1 | ... (or WEEK/MONTH).
+	prefix := retentionColumnPrefix(unit)
 	headers := make([]string, 0, 2+maxCols)
 	headers = append(headers, "DATE", "FIRST")
 	for i := 0; i < maxCols; i++ {
-		headers = append(headers, fmt.Sprintf("DAY %d", i))
+		headers = append(headers, fmt.Sprintf("%s %d", prefix, i))
 	}
 
 	rows := make([][]string, 0, len(dates))
@@ -213,3 +301,12 @@ func renderRetentionTable(result map[string]any) error {
 	output.PrintTable(s.Out, headers, rows, s.IsTerminal())
 	return nil
 }
+
+// retentionColumnPrefix returns the interval column label for a retention
+// unit, e.g. "WEEK" for "week". It defaults to "DAY" when unit is empty.
+func retentionColumnPrefix(unit string) string {
+	if unit == "" {
+		return "DAY"
+	}
+	return strings.ToUpper(unit)
+}
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+	"slices"
 	"sort"
+	"strings"
 
 	"github.com/aviadshiber/mp/internal/client"
 	"github.com/aviadshiber/mp/internal/output"
@@ -17,14 +19,15 @@ func init() {
 
 func newQuerySegmentationCmd() *cobra.Command {
 	var (
-		event     string
-		from      string
-		to        string
-		on        string
-		unit      string
-		where     string
-		queryType string
-		limit     int
+		f          segmentationFlags
+		on         []string
+		queryType  string
+		percent    bool
+		transpose  bool
+		top        int
+		cumulative bool
+		allEvents  bool
+		fill       string
 	)
 
 	cmd := &cobra.Command{
@@ -33,7 +36,41 @@ func newQuerySegmentationCmd() *cobra.Command {
 		Long: `Query event segmentation from the Mixpanel analytics API. Returns event counts
 broken down by time and optionally by a property (using --on).
 
-This is the most commonly used analytics query in Mixpanel.`,
+This is the most commonly used analytics query in Mixpanel.
+
+Pass a comma-separated --event list to compare several events. The API accepts
+one event per request, so mp sends one request per event and merges the
+results client-side into a table keyed by date with one column per event. With
+--on, merged columns are named "<event> / <segment>".
+
+Use the "sum" and "average" subcommands to aggregate a numeric expression per
+date instead of counting events.
+
+--cumulative replaces each period's count in the table with the running total
+from the start of the range. The segmentation API has no cumulative option, so
+mp computes the totals client-side from the per-period counts; --json output
+stays the API's per-period response. Running totals of unique users
+double-count users active in several periods, so --cumulative only works with
+--type general (the default).
+
+Repeat --on once to break down by two properties. mp then queries the
+/segmentation/multiseg endpoint with the first expression as the outer and the
+second as the inner breakdown, and the table names each segment
+"<outer> / <inner>". The API supports at most two levels, and --cumulative
+cannot be combined with two breakdowns. --json returns the nested response.
+
+--all-events counts every event in the project instead of a single event. It
+is shorthand for --event '$all', the API's name for all events, and cannot be
+combined with other event names or with --type average.
+
+--fill controls cells for dates a segment has no value for in table and CSV
+output: "zero" (the default) shows 0, and "blank" leaves the cell empty so
+absent data can be told apart from a true zero. --json returns the API's
+values unchanged.
+
+--top N keeps the N segments with the highest totals over the range and sums
+the rest into an "Other" row. It only affects the table; --json always returns
+every segment.`,
 		Example: `  # Daily signups for January 2024
   mp query segmentation --event "Signup" --from 2024-01-01 --to 2024-01-31
 
@@ -41,6 +78,9 @@ This is the most commonly used analytics query in Mixpanel.`,
   mp query segmentation --event "Signup" --from 2024-01-01 --to 2024-01-31 \
     --on 'properties["country"]'
 
+  # Compare two events over time
+  mp query segmentation --event "Signup,Login" --from 2024-01-01 --to 2024-01-31
+
   # Unique logins per week
   mp query segmentation --event "Login" --from 2024-01-01 --to 2024-01-31 \
     --unit week --type unique
@@ -49,39 +89,273 @@ This is the most commonly used analytics query in Mixpanel.`,
   mp query segmentation --event "Purchase" --from 2024-01-01 --to 2024-01-31 \
     --where 'properties["amount"] > 100' --limit 50
 
+  # Daily count of all events, broken down by platform
+  mp query segmentation --all-events --from 2024-01-01 --to 2024-01-31 \
+    --on 'properties["$os"]'
+
+  # Signups broken down by country and then by platform
+  mp query segmentation --event "Signup" --from 2024-01-01 --to 2024-01-31 \
+    --on 'properties["country"]' --on 'properties["$os"]'
+
+  # Each segment's share of the daily total
+  mp query segmentation --event "Signup" --from 2024-01-01 --to 2024-01-31 \
+    --on 'properties["country"]' --percent
+
+  # Top 10 countries, with the rest summed into "Other"
+  mp query segmentation --event "Signup" --from 2024-01-01 --to 2024-01-31 \
+    --on 'properties["country"]' --top 10
+
+  # Leave dates without data empty instead of 0
+  mp query segmentation --event "Signup" --from 2024-01-01 --to 2024-01-31 \
+    --on 'properties["country"]' --fill blank
+
+  # Running total of signups over the month
+  mp query segmentation --event "Signup" --from 2024-01-01 --to 2024-01-31 --cumulative
+
   # JSON output with jq
   mp query segmentation --event "Signup" --from 2024-01-01 --to 2024-01-31 --json \
     --jq '.data.values'`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runQuerySegmentation(cmd, event, from, to, on, unit, where, queryType, limit)
+			if top < 0 {
+				return fmt.Errorf("`--top` must be a positive number of segments")
+			}
+			if cumulative && queryType != "" && queryType != "general" {
+				return fmt.Errorf("`--cumulative` only works with `--type general`; running totals of %s values are not meaningful", queryType)
+			}
+			if len(on) > 2 {
+				return fmt.Errorf("`--on` can be given at most twice; the API supports two breakdown levels")
+			}
+			if len(on) == 2 && cumulative {
+				return fmt.Errorf("`--cumulative` supports a single `--on` breakdown")
+			}
+			if fill != "zero" && fill != "blank" {
+				return fmt.Errorf("invalid `--fill` %q; must be one of: zero, blank", fill)
+			}
+			if err := validateLimit(f.limit, maxSegmentationLimit); err != nil {
+				return err
+			}
+			opts := segmentationTableOptions{percent: percent, transpose: transpose, top: top, cumulative: cumulative, blankMissing: fill == "blank"}
+			return runQuerySegmentation(cmd, f.event, f.from, f.to, on, f.unit, f.where, queryType, f.limit, opts)
 		},
 	}
 
-	cmd.Flags().StringVar(&event, "event", "", "Event name to segment (required)")
-	cmd.Flags().StringVar(&from, "from", "", "Start date yyyy-mm-dd (required)")
-	cmd.Flags().StringVar(&to, "to", "", "End date yyyy-mm-dd (required)")
-	cmd.Flags().StringVar(&on, "on", "", "Property expression for breakdown (e.g., properties[\"country\"])")
-	cmd.Flags().StringVar(&unit, "unit", "", "Time unit: minute, hour, day, week, month")
-	cmd.Flags().StringVar(&where, "where", "", "Filter expression")
+	// Registered before bind so --all-events fills in the event ahead of the
+	// interactive event picker.
+	chainPreRunE(cmd, func() error {
+		if !allEvents {
+			return nil
+		}
+		if f.event != "" {
+			return fmt.Errorf("`--all-events` cannot be combined with `--event`")
+		}
+		f.event = allEventsName
+		return nil
+	})
+	f.bind(cmd, queryFlagSpec{
+		units:    []string{"minute", "hour", "day", "week", "month"},
+		limit:    "breakdown values",
+		maxLimit: maxSegmentationLimit,
+	})
+	cmd.Flags().Lookup("event").Usage = "Event name to segment, or comma-separated names to compare (required unless --all-events)"
+	cmd.Flags().BoolVar(&allEvents, "all-events", false, "Count all events instead of one (same as --event '$all')")
+	cmd.Flags().StringArrayVar(&on, "on", nil, breakdownHelp+"; repeat once for a two-level breakdown")
 	cmd.Flags().StringVar(&queryType, "type", "", "Aggregation type: general, unique, average")
-	cmd.Flags().IntVar(&limit, "limit", 0, "Maximum number of breakdown values (max 10000)")
+	cmd.Flags().BoolVar(&percent, "percent", false, "Show each segment as a percentage of the date's total")
+	cmd.Flags().BoolVar(&transpose, "transpose", false, "Swap the rows and columns of the table")
+	cmd.Flags().BoolVar(&cumulative, "cumulative", false, "Show running totals over the date range instead of per-period counts (table only)")
+	cmd.Flags().StringVar(&fill, "fill", "zero", "Show dates without data as: zero, blank (table output only)")
+	cmd.Flags().IntVar(&top, "top", 0, "Show only the N highest-total segments and sum the rest into \"Other\" (table output only)")
 
-	_ = cmd.MarkFlagRequired("event")
-	_ = cmd.MarkFlagRequired("from")
-	_ = cmd.MarkFlagRequired("to")
+	cmd.AddCommand(newSegmentationAggregateCmd("sum", "Sum"))
+	cmd.AddCommand(newSegmentationAggregateCmd("average", "Average"))
 
+	addExtraParamsFlag(cmd)
 	return cmd
 }
 
-func runQuerySegmentation(cmd *cobra.Command, event, from, to, on, unit, where, queryType string, limit int) error {
+// segmentationFlags holds the flags shared by the segmentation commands.
+type segmentationFlags struct {
+	event string
+	*queryFlags
+}
+
+// bind registers --event and the common query flags of spec, plus --where, on
+// cmd. A missing event is prompted for in an interactive terminal.
+func (f *segmentationFlags) bind(cmd *cobra.Command, spec queryFlagSpec) {
+	cmd.Flags().StringVar(&f.event, "event", "", "Event name to segment (required)")
+	spec.where = filterHelp
+	f.queryFlags = addCommonQueryFlags(cmd, spec)
+
+	addEventPicker(cmd, &f.event)
+}
+
+// allEventsName is the event name the segmentation API treats as every event
+// in the project.
+const allEventsName = "$all"
+
+func runQuerySegmentation(cmd *cobra.Command, event, from, to string, on []string, unit, where, queryType string, limit int, opts segmentationTableOptions) error {
+	events := splitCSV(event)
+	if len(events) == 0 {
+		return fmt.Errorf("`--event` must specify at least one event name")
+	}
+	if err := validateAllEvents(events, queryType); err != nil {
+		return err
+	}
+
+	// A two-level breakdown goes through multiseg, which takes the
+	// expressions as outer and inner instead of on.
+	path, label, onExpr := "/segmentation", "segmentation", ""
+	if len(on) == 1 {
+		onExpr = on[0]
+	}
+	if len(on) == 2 {
+		path, label = "/segmentation/multiseg", "multi-property segmentation"
+	}
+
 	c, err := newClient()
 	if err != nil {
 		return err
 	}
 
+	// The endpoint takes a single event, so multiple events are fetched in
+	// parallel (up to --concurrency) and merged into a single response.
+	results := make([]map[string]any, len(events))
+	err = forEachConcurrent(len(events), func(i int) error {
+		params, err := segmentationParams(events[i], from, to, onExpr, unit, where)
+		if err != nil {
+			return err
+		}
+		if len(on) == 2 {
+			params.Set("outer", on[0])
+			params.Set("inner", on[1])
+		}
+		if queryType != "" {
+			params.Set("type", queryType)
+		}
+		if limit > 0 {
+			params.Set("limit", fmt.Sprintf("%d", limit))
+		}
+
+		results[i], err = fetchSegmentation(c, path, params, label)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	result := results[0]
+	if len(events) > 1 {
+		result = mergeSegmentationResults(events, results, len(on) > 0)
+		// Show dates as rows and events as columns, like `query events`.
+		opts.transpose = !opts.transpose
+	}
+	// Handle --json output (with optional jq/template).
+	handled, err := handleJSONOutput(cmd, result)
+	if err != nil {
+		return err
+	}
+	if handled {
+		return nil
+	}
+
+	// Default: render as table.
+	return renderSegmentationTable(result, opts)
+}
+
+// mergeSegmentationResults combines per-event segmentation responses into one
+// response of the same shape. Without a breakdown each event contributes a
+// single series named after the event; with one (prefixed), each segment is
+// renamed "<event> / <segment>" so segments from different events stay apart.
+func mergeSegmentationResults(events []string, results []map[string]any, prefixed bool) map[string]any {
+	seen := make(map[string]bool)
+	var series []string
+	values := make(map[string]any)
+
+	for i, result := range results {
+		data, _ := result["data"].(map[string]any)
+		seriesRaw, _ := data["series"].([]any)
+		for _, d := range seriesRaw {
+			ds := fmt.Sprintf("%v", d)
+			if !seen[ds] {
+				seen[ds] = true
+				series = append(series, ds)
+			}
+		}
+
+		valuesRaw, _ := data["values"].(map[string]any)
+		for seg, v := range valuesRaw {
+			name := events[i]
+			if prefixed {
+				name = events[i] + segmentSeparator + seg
+			}
+			values[name] = v
+		}
+	}
+	sort.Strings(series)
+
+	seriesAny := make([]any, len(series))
+	for i, d := range series {
+		seriesAny[i] = d
+	}
+	return map[string]any{
+		"data": map[string]any{
+			"series": seriesAny,
+			"values": values,
+		},
+	}
+}
+
+// cumulateSegments returns a copy of values with each segment's per-date
+// values replaced by running totals in date order. Dates missing from a
+// segment count as zero and are filled in so the running total is visible on
+// every date.
+func cumulateSegments(values map[string]any, dates []string) map[string]any {
+	sorted := slices.Sorted(slices.Values(dates))
+
+	cumulated := make(map[string]any, len(values))
+	for seg, v := range values {
+		byDate, ok := v.(map[string]any)
+		if !ok {
+			cumulated[seg] = v
+			continue
+		}
+		running := make(map[string]any, len(sorted))
+		total := 0.0
+		for _, d := range sorted {
+			if n, ok := output.NumericValue(byDate[d]); ok {
+				total += n
+			}
+			running[d] = total
+		}
+		cumulated[seg] = running
+	}
+	return cumulated
+}
+
+// validateAllEvents rejects uses of allEventsName the API cannot answer: mixed
+// with named events, whose counts it already includes, or with --type average,
+// which has no per-event meaning across all events.
+func validateAllEvents(events []string, queryType string) error {
+	for _, e := range events {
+		if e != allEventsName {
+			continue
+		}
+		if len(events) > 1 {
+			return fmt.Errorf("%q counts every event and cannot be combined with other event names", allEventsName)
+		}
+		if queryType == "average" {
+			return fmt.Errorf("`--type average` is not supported for %q; use general or unique", allEventsName)
+		}
+	}
+	return nil
+}
+
+// segmentationParams builds the query parameters shared by the segmentation
+// family of endpoints. Optional values are omitted when empty.
+func segmentationParams(event, from, to, on, unit, where string) (url.Values, error) {
 	params := url.Values{}
 	if err := addProjectID(params); err != nil {
-		return err
+		return nil, err
 	}
 	params.Set("event", event)
 	params.Set("from_date", from)
@@ -96,46 +370,173 @@ func runQuerySegmentation(cmd *cobra.Command, event, from, to, on, unit, where,
 	if where != "" {
 		params.Set("where", where)
 	}
-	if queryType != "" {
-		params.Set("type", queryType)
-	}
-	if limit > 0 {
-		params.Set("limit", fmt.Sprintf("%d", limit))
+	return params, nil
+}
+
+// fetchSegmentation calls a segmentation-family endpoint and decodes the
+// response with c. label names the query in error messages.
+func fetchSegmentation(c *client.Client, path string, params url.Values, label string) (map[string]any, error) {
+	if err := applyExtraParams(params); err != nil {
+		return nil, err
 	}
 
-	resp, err := c.Get(client.APIFamilyQuery, "/segmentation", params)
+	resp, err := c.Get(client.APIFamilyQuery, path, params)
 	if err != nil {
-		return fmt.Errorf("querying segmentation: %w", err)
+		return nil, fmt.Errorf("querying %s: %w", label, err)
 	}
 
 	body, err := readResponseBody(resp.Body, resp.StatusCode)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var result map[string]any
 	if err := json.Unmarshal(body, &result); err != nil {
-		return fmt.Errorf("parsing segmentation response: %w", err)
+		return nil, fmt.Errorf("parsing %s response: %w", label, err)
 	}
+	if err := checkAPIError(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
 
-	// Handle --json output (with optional jq/template).
-	handled, err := handleJSONOutput(cmd, result)
-	if err != nil {
-		return err
+// segmentationTableOptions controls how renderSegmentationTable presents values.
+// It only affects table output; JSON output always carries the raw response.
+type segmentationTableOptions struct {
+	// percent renders each cell as its share of the date's column total
+	// when the response contains more than one segment.
+	percent bool
+	// transpose swaps the rows and columns of the rendered table.
+	transpose bool
+	// numericSegments orders segments by their leading number instead of
+	// alphabetically, for bucket labels such as "100 - 200".
+	numericSegments bool
+	// top, when positive, keeps the top segments by total and folds the
+	// rest into an "Other" segment.
+	top int
+	// cumulative shows running totals instead of per-period values.
+	cumulative bool
+	// blankMissing leaves cells empty for dates a segment has no value for
+	// instead of showing 0.
+	blankMissing bool
+}
+
+// otherSegment is the label of the segment that --top folds the tail into.
+const otherSegment = "Other"
+
+// otherSegmentLabel returns otherSegment, or when values already has a real
+// segment of that name, the first of "Other (folded)", "Other (folded 2)", ...
+// that is not taken, so the folded tail never replaces a real segment.
+func otherSegmentLabel(values map[string]any) string {
+	label := otherSegment
+	for i := 1; ; i++ {
+		if _, taken := values[label]; !taken {
+			return label
+		}
+		if i == 1 {
+			label = otherSegment + " (folded)"
+		} else {
+			label = fmt.Sprintf("%s (folded %d)", otherSegment, i)
+		}
 	}
-	if handled {
-		return nil
+}
+
+// foldTopSegments keeps the n segments of values with the highest numeric
+// totals across dates and sums the remaining segments per date into an
+// "Other" segment (see otherSegmentLabel). It returns the new values and the
+// segment order: the kept segments in their original order of segments, then
+// "Other".
+func foldTopSegments(values map[string]any, segments, dates []string, n int) (map[string]any, []string) {
+	if n <= 0 || len(segments) <= n {
+		return values, segments
 	}
 
-	// Default: render as table.
-	return renderSegmentationTable(result)
+	totals := make(map[string]float64, len(segments))
+	for _, seg := range segments {
+		segData, _ := values[seg].(map[string]any)
+		for _, date := range dates {
+			if v, ok := output.NumericValue(segData[date]); ok {
+				totals[seg] += v
+			}
+		}
+	}
+
+	ranked := append([]string(nil), segments...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return totals[ranked[i]] > totals[ranked[j]]
+	})
+	keep := make(map[string]bool, n)
+	for _, seg := range ranked[:n] {
+		keep[seg] = true
+	}
+
+	folded := make(map[string]any, n+1)
+	other := make(map[string]any, len(dates))
+	otherSums := make(map[string]float64, len(dates))
+	kept := make([]string, 0, n+1)
+	for _, seg := range segments {
+		if keep[seg] {
+			folded[seg] = values[seg]
+			kept = append(kept, seg)
+			continue
+		}
+		segData, _ := values[seg].(map[string]any)
+		for _, date := range dates {
+			if v, ok := output.NumericValue(segData[date]); ok {
+				otherSums[date] += v
+			}
+		}
+	}
+	for _, date := range dates {
+		other[date] = output.FormatNumber(otherSums[date])
+	}
+	label := otherSegmentLabel(values)
+	folded[label] = other
+	return folded, append(kept, label)
+}
+
+// segmentSeparator joins the keys of nested breakdowns into one segment name.
+const segmentSeparator = " / "
+
+// flattenSegments turns the nested values of a multi-level breakdown, such as
+// {"US": {"iOS": {date: n}}}, into single-level segments named with
+// segmentSeparator, such as {"US / iOS": {date: n}}. A segment is a leaf when
+// none of its values are objects, so single-level values are returned with
+// the same contents.
+func flattenSegments(values map[string]any, prefix string) map[string]any {
+	flat := make(map[string]any, len(values))
+	for key, v := range values {
+		name := key
+		if prefix != "" {
+			name = prefix + segmentSeparator + key
+		}
+		inner, ok := v.(map[string]any)
+		if !ok || !hasNestedSegments(inner) {
+			flat[name] = v
+			continue
+		}
+		for k, leaf := range flattenSegments(inner, name) {
+			flat[k] = leaf
+		}
+	}
+	return flat
+}
+
+// hasNestedSegments reports whether any value of m is itself an object.
+func hasNestedSegments(m map[string]any) bool {
+	for _, v := range m {
+		if _, ok := v.(map[string]any); ok {
+			return true
+		}
+	}
+	return false
 }
 
 // renderSegmentationTable renders segmentation data as a human-readable table.
 // The response shape is:
 //
 //	{"data": {"series": [...dates], "values": {segmentName: {date: count}}}}
-func renderSegmentationTable(result map[string]any) error {
+func renderSegmentationTable(result map[string]any, opts segmentationTableOptions) error {
 	s := getIO()
 
 	data, ok := result["data"].(map[string]any)
@@ -145,10 +546,10 @@ func renderSegmentationTable(result map[string]any) error {
 
 	seriesRaw, _ := data["series"].([]any)
 	valuesRaw, _ := data["values"].(map[string]any)
+	valuesRaw = flattenSegments(valuesRaw, "")
 
 	if len(seriesRaw) == 0 || len(valuesRaw) == 0 {
-		s.Printf("No data returned.\n")
-		return nil
+		return emptyResult(s, "No data returned.")
 	}
 
 	// Build date list from series.
@@ -162,44 +563,51 @@ func renderSegmentationTable(result map[string]any) error {
 	for seg := range valuesRaw {
 		segments = append(segments, seg)
 	}
-	sort.Strings(segments)
-
-	// If there is only one segment (no breakdown), show a simple Date | Count table.
-	if len(segments) == 1 {
-		headers := []string{"DATE", "COUNT"}
-		segData, _ := valuesRaw[segments[0]].(map[string]any)
-		rows := make([][]string, 0, len(dates))
-		for _, date := range dates {
-			count := "0"
-			if v, exists := segData[date]; exists {
-				count = fmt.Sprintf("%v", v)
-			}
-			rows = append(rows, []string{date, count})
-		}
-		output.PrintTable(s.Out, headers, rows, s.IsTerminal())
-		return nil
+	if opts.numericSegments {
+		sortNumericLabels(segments)
+	} else {
+		sort.Strings(segments)
+	}
+	valuesRaw, segments = foldTopSegments(valuesRaw, segments, dates, opts.top)
+	if opts.cumulative {
+		valuesRaw = cumulateSegments(valuesRaw, dates)
 	}
 
-	// Multiple segments: show Segment | date1 | date2 | ...
-	headers := make([]string, 0, 1+len(dates))
-	headers = append(headers, "SEGMENT")
-	headers = append(headers, dates...)
+	// Segments are rows and dates columns; a single segment (no breakdown)
+	// is shown as a simple Date | Count table.
+	output.RenderMap2D(s.Out, dates, valuesRaw, output.Map2DOptions{
+		Order:        segments,
+		SeriesHeader: "SEGMENT",
+		SeriesAsRows: true,
+		SingleHeader: "COUNT",
+		Transpose:    opts.transpose,
+		Percent:      opts.percent,
+		BlankMissing: opts.blankMissing,
+	}, s.IsTerminal())
+	return nil
+}
 
-	rows := make([][]string, 0, len(segments))
-	for _, seg := range segments {
-		segData, _ := valuesRaw[seg].(map[string]any)
-		row := make([]string, 0, 1+len(dates))
-		row = append(row, seg)
-		for _, date := range dates {
-			val := "0"
-			if v, exists := segData[date]; exists {
-				val = fmt.Sprintf("%v", v)
-			}
-			row = append(row, val)
+// sortNumericLabels sorts labels by their leading number (ignoring thousands
+// separators), so "900 - 1,000" sorts before "1,000 - 1,100". Labels without
+// a leading number sort after numeric ones, alphabetically.
+func sortNumericLabels(labels []string) {
+	lead := func(label string) (float64, bool) {
+		field := strings.Fields(label)
+		if len(field) == 0 {
+			return 0, false
 		}
-		rows = append(rows, row)
+		return output.NumericValue(strings.ReplaceAll(field[0], ",", ""))
 	}
-
-	output.PrintTable(s.Out, headers, rows, s.IsTerminal())
-	return nil
+	sort.SliceStable(labels, func(i, j int) bool {
+		a, aok := lead(labels[i])
+		b, bok := lead(labels[j])
+		switch {
+		case aok && bok:
+			return a < b
+		case aok != bok:
+			return aok
+		default:
+			return labels[i] < labels[j]
+		}
+	})
 }
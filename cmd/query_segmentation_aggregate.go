@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aviadshiber/mp/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// newSegmentationAggregateCmd builds the "sum" and "average" segmentation
+// subcommands, which call /segmentation/<name> and return one numeric value
+// per date. title is the capitalized name used in help text.
+func newSegmentationAggregateCmd(name, title string) *cobra.Command {
+	var f segmentationFlags
+
+	lower := strings.ToLower(title)
+	cmd := &cobra.Command{
+		Use:   name,
+		Short: fmt.Sprintf("%s a numeric expression per date", title),
+		Long: fmt.Sprintf(`%s a numeric expression over an event's occurrences for each date in the
+range, using the /segmentation/%s endpoint. Useful for revenue-over-time style
+queries.`, title, name),
+		Example: fmt.Sprintf(`  # Daily %s of purchase amounts
+  mp query segmentation %s --event "Purchase" --from 2024-01-01 --to 2024-01-31 \
+    --on 'properties["amount"]'
+
+  # Hourly, filtered
+  mp query segmentation %s --event "Purchase" --from 2024-01-01 --to 2024-01-02 \
+    --on 'properties["amount"]' --unit hour --where 'properties["country"]=="US"'
+
+  # JSON output
+  mp query segmentation %s --event "Purchase" --from 2024-01-01 --to 2024-01-31 \
+    --on 'properties["amount"]' --json`, lower, name, name, name),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSegmentationAggregate(cmd, name, f)
+		},
+	}
+
+	f.bind(cmd, queryFlagSpec{
+		units: []string{"hour", "day"},
+		on:    fmt.Sprintf("Numeric expression to %s (required)", lower),
+	})
+	_ = cmd.MarkFlagRequired("on")
+
+	addExtraParamsFlag(cmd)
+	return cmd
+}
+
+func runSegmentationAggregate(cmd *cobra.Command, name string, f segmentationFlags) error {
+	params, err := segmentationParams(f.event, f.from, f.to, f.on, f.unit, f.where)
+	if err != nil {
+		return err
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	result, err := fetchSegmentation(c, "/segmentation/"+name, params, "segmentation "+name)
+	if err != nil {
+		return err
+	}
+
+	handled, err := handleJSONOutput(cmd, result)
+	if err != nil {
+		return err
+	}
+	if handled {
+		return nil
+	}
+
+	return renderSegmentationAggregate(result)
+}
+
+// renderSegmentationAggregate renders a DATE | VALUE table.
+// Response shape: {"status": "ok", "results": {date: value}}
+func renderSegmentationAggregate(result map[string]any) error {
+	s := getIO()
+
+	results, ok := result["results"].(map[string]any)
+	if !ok {
+		return output.PrintJSON(s.Out, result)
+	}
+	if len(results) == 0 {
+		return emptyResult(s, "No data returned.")
+	}
+
+	dates := make([]string, 0, len(results))
+	for d := range results {
+		dates = append(dates, d)
+	}
+	sort.Strings(dates)
+
+	headers := []string{"DATE", "VALUE"}
+	rows := make([][]string, 0, len(dates))
+	for _, d := range dates {
+		rows = append(rows, []string{d, fmt.Sprintf("%v", results[d])})
+	}
+
+	output.PrintTable(s.Out, headers, rows, s.IsTerminal())
+	return nil
+}
@@ -2,11 +2,17 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/aviadshiber/mp/internal/client"
+	"github.com/aviadshiber/mp/internal/config"
 	"github.com/aviadshiber/mp/internal/iostreams"
+	"github.com/aviadshiber/mp/internal/output"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -26,6 +32,21 @@ var (
 	cfgJSON      string
 	cfgJQ        string
 	cfgTemplate  string
+	cfgVerbose   int
+	cfgFile      string
+	cfgCacheTTL  time.Duration
+	cfgNoCache   bool
+
+	cfgFailOnEmpty bool
+
+	// cfgRequestTimeout is the request timeout of the running command,
+	// resolved by requestTimeout before it runs.
+	cfgRequestTimeout = client.DefaultTimeout
+
+	cfgEnvFile string
+	// envFileErr records a --env-file load failure from initConfig, which
+	// cannot return errors, so PersistentPreRunE can report it.
+	envFileErr error
 
 	io *iostreams.IOStreams
 )
@@ -46,13 +67,47 @@ It supports querying analytics, exporting raw events, managing user profiles,
 and inspecting project metadata. Output can be formatted as JSON, tables, CSV,
 or filtered with jq expressions and Go templates.
 
-Configuration is stored in ~/.config/mp/config.yaml and can be overridden
-with flags or environment variables (MP_PROJECT_ID, MP_REGION, MP_TOKEN).`,
+Configuration is stored in $XDG_CONFIG_HOME/mp/config.yaml (default
+~/.config/mp/config.yaml), or the file named by --config or MP_CONFIG_FILE, and
+can be overridden with flags or environment variables (MP_PROJECT_ID, MP_REGION,
+MP_TOKEN).
+
+Exit status: 0 on success, 1 on an unclassified error, 2 when a query returns
+no data and --fail-on-empty is set, 3 on an authentication error (HTTP 401/403),
+4 on another client error (HTTP 4xx), and 5 on a server error (HTTP 5xx).`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		io = iostreams.New()
 		io.SetQuiet(viper.GetBool("quiet"))
+		humanize, _ := cmd.Flags().GetBool("humanize")
+		output.SetHumanizeNumbers(humanize)
+		output.SetTableWidth(io.TerminalWidth())
+		noHeader, _ := cmd.Flags().GetBool("no-header")
+		output.SetNoHeader(noHeader)
+		output.SetCompactJSON(compactJSONOutput(cmd, io.IsTerminal()))
+
+		if envFileErr != nil {
+			return envFileErr
+		}
+
+		if err := validateJSONFlags(cmd); err != nil {
+			return err
+		}
+
+		if n := viper.GetInt("concurrency"); n < 1 || n > config.MaxConcurrency {
+			return fmt.Errorf("invalid concurrency %d; must be from 1 to %d", n, config.MaxConcurrency)
+		}
+
+		timeout, err := requestTimeout(cmd)
+		if err != nil {
+			return err
+		}
+		cfgRequestTimeout = timeout
+
+		if n := viper.GetInt64("max_response_bytes"); n < 0 {
+			return fmt.Errorf("invalid max_response_bytes %d; must not be negative", n)
+		}
 
 		// Validate region if provided.
 		region := viper.GetString("region")
@@ -67,13 +122,14 @@ with flags or environment variables (MP_PROJECT_ID, MP_REGION, MP_TOKEN).`,
 }
 
 func init() {
-	// Load config file into global viper.
-	home, _ := os.UserHomeDir()
-	if home != "" {
-		viper.SetConfigFile(home + "/.config/mp/config.yaml")
-		viper.SetConfigType("yaml")
-		_ = viper.ReadInConfig() // Ignore error if file doesn't exist yet.
-	}
+	// The config file is loaded after flag parsing so --config can select it.
+	cobra.OnInitialize(initConfig)
+
+	// Defaults for keys that are on unless explicitly disabled.
+	viper.SetDefault("retry_jitter", true)
+	viper.SetDefault("concurrency", 4)
+	viper.SetDefault("requests_per_second", client.DefaultRequestsPerSecond)
+	viper.SetDefault("max_response_bytes", defaultMaxResponseBytes)
 
 	// Bind env vars before flag parsing.
 	viper.SetEnvPrefix("MP")
@@ -82,12 +138,35 @@ func init() {
 
 	// Persistent flags available to all subcommands.
 	pf := rootCmd.PersistentFlags()
-	pf.StringVarP(&cfgProjectID, "project-id", "p", "", "Mixpanel project ID (env: MP_PROJECT_ID)")
+	pf.StringVar(&cfgFile, "config", "", "Path to the config file (env: MP_CONFIG_FILE)")
+	pf.StringVar(&cfgEnvFile, "env-file", "", "Load MP_* variables from a dotenv file; real env vars and flags take precedence")
+	pf.StringVarP(&cfgProjectID, "project-id", "p", "", "Mixpanel project ID; precedence: flag > MP_PROJECT_ID env > config file")
 	pf.StringVarP(&cfgRegion, "region", "r", "", "API region: us, eu, in (env: MP_REGION)")
 	pf.BoolVarP(&cfgQuiet, "quiet", "q", false, "Suppress non-essential output (env: MP_QUIET)")
+	pf.CountVarP(&cfgVerbose, "verbose", "v", "Log request/response summaries to stderr; repeat (-vv) to include redacted headers")
 	pf.StringVar(&cfgJSON, "json", "", "Output JSON; optionally comma-separated field list")
-	pf.StringVar(&cfgJQ, "jq", "", "Filter JSON output with a jq expression (requires --json)")
-	pf.StringVar(&cfgTemplate, "template", "", "Format output with a Go template (requires --json)")
+	pf.StringVar(&cfgJQ, "jq", "", "Filter JSON output with a jq expression (implies --json)")
+	pf.StringVar(&cfgTemplate, "template", "", "Format JSON output with a Go template (implies --json)")
+	pf.String("template-preset", "", "Format JSON output with a named template: "+strings.Join(output.TemplatePresetNames(), ", ")+", or your own (implies --json)")
+	pf.Bool("humanize", false, "Add thousands separators to numbers in terminal tables")
+	pf.Bool("no-header", false, "Omit the header row from table, TSV, and CSV output")
+	pf.Bool("compact", false, "Print JSON on a single line (default when stdout is not a terminal)")
+	pf.Bool("pretty", false, "Print indented JSON even when stdout is not a terminal")
+	rootCmd.MarkFlagsMutuallyExclusive("compact", "pretty")
+	pf.Bool("flatten", false, "Flatten nested JSON into dot-separated keys such as a.b.0.c (implies --json)")
+
+	pf.Bool("cache", false, "Cache metadata responses on disk (env: MP_CACHE)")
+	pf.DurationVar(&cfgCacheTTL, "cache-ttl", 5*time.Minute, "How long metadata responses are cached; 0 disables (env: MP_CACHE_TTL)")
+	pf.BoolVar(&cfgNoCache, "no-cache", false, "Bypass the local metadata cache")
+	pf.BoolVar(&cfgFailOnEmpty, "fail-on-empty", false, "Exit with status 2 when a query returns no data")
+	pf.Float64("rate-limit", client.DefaultRequestsPerSecond, "Maximum API requests per second; 0 disables (env: MP_REQUESTS_PER_SECOND)")
+	pf.Int64("max-response-bytes", defaultMaxResponseBytes, "Fail when a buffered API response exceeds this many bytes; 0 disables (env: MP_MAX_RESPONSE_BYTES)")
+	pf.Duration("timeout", 0, "Per-request timeout, e.g. 30s or 5m; 0 disables (default: 30s for metadata commands, none for export and profiles, 2m otherwise; env: MP_TIMEOUT)")
+	pf.Int("concurrency", 4, fmt.Sprintf("Maximum parallel API requests for commands that fetch in parallel (1-%d; env: MP_CONCURRENCY)", config.MaxConcurrency))
+
+	pf.String("ca-cert", "", "PEM file of extra CA certificates to trust, e.g. for a TLS-inspecting proxy (env: MP_CA_CERT)")
+	pf.Bool("insecure-skip-verify", false, "Skip TLS certificate verification; only for test proxies with self-signed certificates (env: MP_INSECURE)")
+	_ = pf.MarkHidden("insecure-skip-verify")
 
 	// Allow --json to be used without a value (e.g., "mp version --json").
 	pf.Lookup("json").NoOptDefVal = " "
@@ -96,17 +175,64 @@ func init() {
 	_ = viper.BindPFlag("project_id", pf.Lookup("project-id"))
 	_ = viper.BindPFlag("region", pf.Lookup("region"))
 	_ = viper.BindPFlag("quiet", pf.Lookup("quiet"))
+	_ = viper.BindPFlag("cache", pf.Lookup("cache"))
+	_ = viper.BindPFlag("cache_ttl", pf.Lookup("cache-ttl"))
+	_ = viper.BindPFlag("concurrency", pf.Lookup("concurrency"))
+	_ = viper.BindPFlag("timeout", pf.Lookup("timeout"))
+	_ = viper.BindPFlag("requests_per_second", pf.Lookup("rate-limit"))
+	_ = viper.BindPFlag("max_response_bytes", pf.Lookup("max-response-bytes"))
+	_ = viper.BindPFlag("insecure", pf.Lookup("insecure-skip-verify"))
+	_ = viper.BindPFlag("ca_cert", pf.Lookup("ca-cert"))
 
 	// Register subcommands.
 	rootCmd.AddCommand(newVersionCmd())
 	rootCmd.AddCommand(newConfigCmd())
 }
 
+// initConfig loads the config file into the global viper instance, using the
+// same path resolution as `mp config` so reads and writes never diverge.
+func initConfig() {
+	if cfgEnvFile != "" {
+		envFileErr = config.LoadEnvFile(cfgEnvFile)
+	}
+
+	path, err := configFilePath()
+	if err != nil {
+		return
+	}
+	viper.SetConfigFile(path)
+	viper.SetConfigType("yaml")
+	_ = viper.ReadInConfig() // Ignore error if file doesn't exist yet.
+}
+
+// configFilePath returns the --config flag value if set, otherwise the
+// default location resolved by the config package.
+func configFilePath() (string, error) {
+	if cfgFile != "" {
+		return cfgFile, nil
+	}
+	return config.Path()
+}
+
+// openConfig opens the config file selected by configFilePath for reading
+// and writing.
+func openConfig() (*config.Config, error) {
+	path, err := configFilePath()
+	if err != nil {
+		return nil, err
+	}
+	return config.Open(path)
+}
+
 // Execute runs the root command. Called from main.
 func Execute() error {
 	if err := rootCmd.Execute(); err != nil {
 		// Print error in red to stderr.
 		s := iostreams.New()
+		if errors.Is(err, errEmptyResult) {
+			// The "no data" message was already printed by the command.
+			return err
+		}
 		fmt.Fprintln(s.ErrOut, s.Failure("Error: "+err.Error()))
 		return err
 	}
@@ -126,7 +252,61 @@ func isDebug() bool {
 	return os.Getenv("MP_DEBUG") == "1"
 }
 
-// jsonOutputRequested reports whether the --json flag was explicitly set.
+// verbosity returns the client logging level from --verbose, treating
+// MP_DEBUG=1 as an alias for a single -v.
+func verbosity() int {
+	if isDebug() && cfgVerbose < 1 {
+		return 1
+	}
+	return cfgVerbose
+}
+
+// jsonImpliedNoticeOnce makes the "--jq/--template imply --json" notice print
+// at most once per process.
+var jsonImpliedNoticeOnce sync.Once
+
+// validateJSONFlags rejects --jq combined with --template. When either is
+// used without --json, it also prints a notice that they now imply JSON
+// output, since they used to be ignored without --json. Passing --json or
+// --quiet silences the notice.
+func validateJSONFlags(cmd *cobra.Command) error {
+	f := cmd.Flags()
+	jq, tmpl := f.Changed("jq"), f.Changed("template")
+	if jq && tmpl {
+		return fmt.Errorf("`--jq` and `--template` cannot be used together")
+	}
+	if f.Changed("template-preset") && (jq || tmpl) {
+		return fmt.Errorf("`--template-preset` cannot be combined with `--jq` or `--template`")
+	}
+	if (!jq && !tmpl) || f.Changed("json") || io.IsQuiet() {
+		return nil
+	}
+
+	jsonImpliedNoticeOnce.Do(func() {
+		io.Errorf("%s `--jq` and `--template` imply `--json`; pass `--json` to silence this notice\n", io.Warning("note:"))
+	})
+	return nil
+}
+
+// compactJSONOutput reports whether JSON should be printed compactly: when
+// --compact is set, or by default when stdout is not a terminal and --pretty
+// is not set.
+func compactJSONOutput(cmd *cobra.Command, isTTY bool) bool {
+	f := cmd.Flags()
+	switch {
+	case f.Changed("compact"):
+		compact, _ := f.GetBool("compact")
+		return compact
+	case f.Changed("pretty"):
+		pretty, _ := f.GetBool("pretty")
+		return !pretty
+	}
+	return !isTTY
+}
+
+// jsonOutputRequested reports whether JSON output was requested, either with
+// --json or implicitly with --jq, --template, --template-preset, or --flatten.
 func jsonOutputRequested(cmd *cobra.Command) bool {
-	return cmd.Flags().Changed("json")
+	f := cmd.Flags()
+	return f.Changed("json") || f.Changed("jq") || f.Changed("template") || f.Changed("template-preset") || f.Changed("flatten")
 }
@@ -21,8 +21,11 @@ func newSchemasCmd() *cobra.Command {
 		Long:  "List and inspect event and profile schemas in your Mixpanel project.",
 	}
 
+	setRequestTimeout(schemasCmd, metadataRequestTimeout)
+
 	schemasCmd.AddCommand(newSchemasListCmd())
 	schemasCmd.AddCommand(newSchemasGetCmd())
+	schemasCmd.AddCommand(newSchemasDiffCmd())
 	return schemasCmd
 }
 
@@ -111,8 +114,7 @@ func renderSchemasList(result map[string]any, detailed bool) error {
 
 	resultsRaw, ok := result["results"].([]any)
 	if !ok || len(resultsRaw) == 0 {
-		s.Printf("No schemas found.\n")
-		return nil
+		return emptyResult(s, "No schemas found.")
 	}
 
 	if detailed && len(resultsRaw) == 1 {
@@ -237,39 +239,49 @@ property definitions.`,
 }
 
 func runSchemasGet(cmd *cobra.Command, entityType, name string) error {
-	c, err := newClient()
+	pid, err := requireProjectID()
 	if err != nil {
 		return err
 	}
 
-	pid, err := requireProjectID()
+	result, err := fetchSchema(pid, entityType, name)
+	if err != nil {
+		return err
+	}
+
+	handled, err := handleJSONOutput(cmd, result)
 	if err != nil {
 		return err
 	}
+	if handled {
+		return nil
+	}
+
+	return renderSchemasList(result, true)
+}
+
+// fetchSchema gets the schema for entityType/name in project pid.
+// Response shape: {"results": [{"entityType": "...", "name": "...", "schemaJson": {...}}]}
+func fetchSchema(pid, entityType, name string) (map[string]any, error) {
+	c, err := newClient()
+	if err != nil {
+		return nil, err
+	}
 
 	path := fmt.Sprintf("/projects/%s/schemas/%s/%s", pid, entityType, name)
 	resp, err := c.Get(client.APIFamilyApp, path, nil)
 	if err != nil {
-		return fmt.Errorf("getting schema: %w", err)
+		return nil, fmt.Errorf("getting schema: %w", err)
 	}
 
 	body, err := readResponseBody(resp.Body, resp.StatusCode)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var result map[string]any
 	if err := json.Unmarshal(body, &result); err != nil {
-		return fmt.Errorf("parsing schema response: %w", err)
+		return nil, fmt.Errorf("parsing schema response: %w", err)
 	}
-
-	handled, err := handleJSONOutput(cmd, result)
-	if err != nil {
-		return err
-	}
-	if handled {
-		return nil
-	}
-
-	return renderSchemasList(result, true)
+	return result, nil
 }
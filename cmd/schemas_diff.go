@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+func newSchemasDiffCmd() *cobra.Command {
+	var (
+		entityType     string
+		name           string
+		againstProject string
+		againstFile    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare a schema with another project or a local file",
+		Long: `Compare the schema for an event or profile in the current project with the
+same schema in another project (--against-project) or in a local JSON file
+(--against-file) saved from "mp schemas get --json".
+
+The diff is property-level: added and removed properties, and properties whose
+type or description changed. "+" lines exist only in the other side, "-" lines
+only in the current project.`,
+		Example: `  # Compare the Signup schema with the production project
+  mp schemas diff --entity-type event --name "Signup" --against-project 12345
+
+  # Compare with a saved copy
+  mp schemas get --entity-type event --name "Signup" --json > signup.json
+  mp schemas diff --entity-type event --name "Signup" --against-file signup.json
+
+  # Structured JSON diff
+  mp schemas diff --entity-type event --name "Signup" --against-project 12345 --json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (againstProject == "") == (againstFile == "") {
+				return fmt.Errorf("exactly one of `--against-project` or `--against-file` is required")
+			}
+			return runSchemasDiff(cmd, entityType, name, againstProject, againstFile)
+		},
+	}
+
+	cmd.Flags().StringVar(&entityType, "entity-type", "", "Entity type: event, profile (required)")
+	cmd.Flags().StringVar(&name, "name", "", "Schema name (required)")
+	cmd.Flags().StringVar(&againstProject, "against-project", "", "Project ID to compare against")
+	cmd.Flags().StringVar(&againstFile, "against-file", "", "Schema JSON file to compare against")
+
+	_ = cmd.MarkFlagRequired("entity-type")
+	_ = cmd.MarkFlagRequired("name")
+
+	return cmd
+}
+
+// schemaChange is a property whose type or description differs between the
+// two schemas.
+type schemaChange struct {
+	Property string `json:"property"`
+	Field    string `json:"field"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+}
+
+// schemaDiff is the property-level difference between two schemas.
+type schemaDiff struct {
+	Added   []string       `json:"added"`
+	Removed []string       `json:"removed"`
+	Changed []schemaChange `json:"changed"`
+}
+
+func runSchemasDiff(cmd *cobra.Command, entityType, name, againstProject, againstFile string) error {
+	pid, err := requireProjectID()
+	if err != nil {
+		return err
+	}
+
+	base, err := fetchSchema(pid, entityType, name)
+	if err != nil {
+		return err
+	}
+
+	var other map[string]any
+	if againstFile != "" {
+		data, err := os.ReadFile(againstFile)
+		if err != nil {
+			return fmt.Errorf("reading schema file: %w", err)
+		}
+		if err := json.Unmarshal(data, &other); err != nil {
+			return fmt.Errorf("parsing schema file: %w", err)
+		}
+	} else {
+		other, err = fetchSchema(againstProject, entityType, name)
+		if err != nil {
+			return err
+		}
+	}
+
+	diff := diffSchemaProperties(schemaProperties(base), schemaProperties(other))
+
+	handled, err := handleJSONOutput(cmd, diff)
+	if err != nil {
+		return err
+	}
+	if handled {
+		return nil
+	}
+
+	return renderSchemaDiff(diff)
+}
+
+// schemaProperties returns the property definitions of the first schema in a
+// schemas response, keyed by property name.
+func schemaProperties(result map[string]any) map[string]map[string]any {
+	props := make(map[string]map[string]any)
+	results, _ := result["results"].([]any)
+	if len(results) == 0 {
+		return props
+	}
+	schema, _ := results[0].(map[string]any)
+	schemaJSON, _ := schema["schemaJson"].(map[string]any)
+	raw, _ := schemaJSON["properties"].(map[string]any)
+	for k, v := range raw {
+		def, _ := v.(map[string]any)
+		props[k] = def
+	}
+	return props
+}
+
+// diffSchemaProperties compares base with other. Added properties exist only
+// in other, removed ones only in base.
+func diffSchemaProperties(base, other map[string]map[string]any) schemaDiff {
+	diff := schemaDiff{Added: []string{}, Removed: []string{}, Changed: []schemaChange{}}
+
+	for prop := range other {
+		if _, ok := base[prop]; !ok {
+			diff.Added = append(diff.Added, prop)
+		}
+	}
+	for prop, def := range base {
+		otherDef, ok := other[prop]
+		if !ok {
+			diff.Removed = append(diff.Removed, prop)
+			continue
+		}
+		for _, field := range []string{"type", "description"} {
+			from, _ := def[field].(string)
+			to, _ := otherDef[field].(string)
+			if from != to {
+				diff.Changed = append(diff.Changed, schemaChange{Property: prop, Field: field, From: from, To: to})
+			}
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool {
+		if diff.Changed[i].Property != diff.Changed[j].Property {
+			return diff.Changed[i].Property < diff.Changed[j].Property
+		}
+		return diff.Changed[i].Field < diff.Changed[j].Field
+	})
+	return diff
+}
+
+// renderSchemaDiff prints a unified-style diff, colorized in a terminal.
+func renderSchemaDiff(diff schemaDiff) error {
+	s := getIO()
+
+	if len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Changed) == 0 {
+		return emptyResult(s, "Schemas are identical.")
+	}
+
+	for _, prop := range diff.Removed {
+		s.Printf("%s\n", s.Failure("- "+prop))
+	}
+	for _, prop := range diff.Added {
+		s.Printf("%s\n", s.Success("+ "+prop))
+	}
+	for _, ch := range diff.Changed {
+		s.Printf("%s\n", s.Warning(fmt.Sprintf("~ %s %s: %q -> %q", ch.Property, ch.Field, ch.From, ch.To)))
+	}
+	return nil
+}
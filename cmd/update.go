@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"errors"
+	"runtime"
+
+	"github.com/aviadshiber/mp/internal/prompt"
+	"github.com/aviadshiber/mp/internal/selfupdate"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(newUpdateCmd())
+}
+
+// updateReport is what `mp update --json` prints.
+type updateReport struct {
+	Current         string `json:"current"`
+	Latest          string `json:"latest"`
+	UpdateAvailable bool   `json:"update_available"`
+	Updated         bool   `json:"updated"`
+	Path            string `json:"path,omitempty"`
+	ReleaseURL      string `json:"release_url"`
+}
+
+func newUpdateCmd() *cobra.Command {
+	var (
+		check bool
+		yes   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update mp to the latest release",
+		Long: `Download the latest mp release from GitHub for this OS and architecture and
+replace the running binary with it.
+
+The release archive is verified against the release's checksums.txt before
+anything is installed, and the binary is replaced atomically: the new one is
+written next to the old one and renamed over it. The command refuses to run if
+the binary's directory is not writable; in that case rerun it with sufficient
+permissions or update mp the way you installed it.
+
+In an interactive terminal mp asks for confirmation before installing. Pass
+--yes to skip the prompt, which is required when stdin is not a terminal.
+With --check, mp only reports whether a newer release exists.`,
+		Example: `  # Check for a newer release without installing it
+  mp update --check
+
+  # Update, confirming interactively
+  mp update
+
+  # Update from a script
+  mp update --yes --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runUpdate(cmd, check, yes)
+		},
+	}
+
+	cmd.Flags().BoolVar(&check, "check", false, "Only report whether a newer release is available")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Install without asking for confirmation")
+	cmd.MarkFlagsMutuallyExclusive("check", "yes")
+	return cmd
+}
+
+func runUpdate(cmd *cobra.Command, check, yes bool) error {
+	s := getIO()
+
+	release, err := selfupdate.Latest()
+	if err != nil {
+		return err
+	}
+	report := updateReport{
+		Current:         versionInfo.version,
+		Latest:          release.Version(),
+		UpdateAvailable: selfupdate.Newer(release.Version(), versionInfo.version),
+		ReleaseURL:      release.URL,
+	}
+
+	if check || !report.UpdateAvailable {
+		handled, err := handleJSONOutput(cmd, report)
+		if err != nil || handled {
+			return err
+		}
+		switch {
+		case !report.UpdateAvailable:
+			s.Printf("%s mp %s is the latest release\n", s.Success(""), report.Current)
+		default:
+			s.Printf("mp %s is available (installed: %s)\n", report.Latest, report.Current)
+			s.Printf("Run mp update to install it; release notes: %s\n", report.ReleaseURL)
+		}
+		return nil
+	}
+
+	exe, err := selfupdate.Executable()
+	if err != nil {
+		return err
+	}
+	report.Path = exe
+	if err := selfupdate.CheckWritable(exe); err != nil {
+		return err
+	}
+
+	if !yes {
+		if !s.CanPrompt() {
+			return errors.New("not running interactively; pass `--yes` to install the update")
+		}
+		ok, err := prompt.Confirm(s.In, s.ErrOut,
+			"Update mp "+report.Current+" to "+report.Latest+" at "+exe+"?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("update cancelled")
+		}
+	}
+
+	archive := selfupdate.ArchiveName(release.Version(), runtime.GOOS, runtime.GOARCH)
+	s.Errorf("Downloading %s...\n", archive)
+	binary, err := selfupdate.Download(release, archive)
+	if err != nil {
+		return err
+	}
+	if err := selfupdate.Replace(exe, binary); err != nil {
+		return err
+	}
+	report.Updated = true
+
+	handled, err := handleJSONOutput(cmd, report)
+	if err != nil || handled {
+		return err
+	}
+	s.Printf("%s Updated mp %s to %s at %s\n", s.Success(""), report.Current, report.Latest, exe)
+	return nil
+}
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"github.com/aviadshiber/mp/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -13,13 +12,17 @@ func newVersionCmd() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			s := getIO()
 
-			if jsonOutputRequested(cmd) {
-				data := map[string]any{
-					"version": versionInfo.version,
-					"commit":  versionInfo.commit,
-					"date":    versionInfo.date,
-				}
-				return output.PrintJSON(s.Out, data)
+			data := map[string]any{
+				"version": versionInfo.version,
+				"commit":  versionInfo.commit,
+				"date":    versionInfo.date,
+			}
+			handled, err := handleJSONOutput(cmd, data)
+			if err != nil {
+				return err
+			}
+			if handled {
+				return nil
 			}
 
 			s.Printf("mp version %s (commit: %s, built: %s)\n",
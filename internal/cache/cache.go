@@ -0,0 +1,140 @@
+// Package cache provides a small on-disk cache for API responses that change
+// rarely, such as project metadata. Entries expire after a fixed TTL.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Cache stores response bodies as files in a directory, one file per key.
+type Cache struct {
+	dir string
+	ttl time.Duration
+}
+
+// New returns a Cache rooted at dir whose entries expire after ttl.
+// The directory is created on first write.
+func New(dir string, ttl time.Duration) *Cache {
+	return &Cache{dir: dir, ttl: ttl}
+}
+
+// Get returns the cached body for key if present and younger than the TTL.
+func (c *Cache) Get(key string) ([]byte, bool) {
+	path := c.path(key)
+	info, err := os.Stat(path)
+	if err != nil || time.Since(info.ModTime()) > c.ttl {
+		return nil, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// Validators are the HTTP validators of a cached response, used to revalidate
+// an expired entry with a conditional request.
+type Validators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// IsZero reports whether v holds no validators.
+func (v Validators) IsZero() bool {
+	return v.ETag == "" && v.LastModified == ""
+}
+
+// GetStale returns the cached body for key regardless of age, together with
+// the validators stored for it by SetWithValidators.
+func (c *Cache) GetStale(key string) ([]byte, Validators, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, Validators{}, false
+	}
+	var v Validators
+	if meta, err := os.ReadFile(c.metaPath(key)); err == nil {
+		_ = json.Unmarshal(meta, &v)
+	}
+	return data, v, true
+}
+
+// Touch marks the entry for key as fresh again, e.g. after the server
+// confirmed it is unchanged.
+func (c *Cache) Touch(key string) error {
+	now := time.Now()
+	return os.Chtimes(c.path(key), now, now)
+}
+
+// SetWithValidators stores data under key like Set, along with the response
+// validators. Zero validators remove any previously stored ones.
+func (c *Cache) SetWithValidators(key string, data []byte, v Validators) error {
+	if err := c.Set(key, data); err != nil {
+		return err
+	}
+	if v.IsZero() {
+		if err := os.Remove(c.metaPath(key)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("removing cache validators: %w", err)
+		}
+		return nil
+	}
+	meta, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("encoding cache validators: %w", err)
+	}
+	return c.writeFile(c.metaPath(key), meta)
+}
+
+// Set stores data under key, replacing any existing entry.
+func (c *Cache) Set(key string, data []byte) error {
+	return c.writeFile(c.path(key), data)
+}
+
+// writeFile atomically replaces the file at path with data.
+func (c *Cache) writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return fmt.Errorf("creating cache directory %s: %w", c.dir, err)
+	}
+
+	// Write to a temp file and rename so readers never see a partial entry.
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Clear removes every entry in dir. A missing directory is not an error.
+func Clear(dir string) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("clearing cache %s: %w", dir, err)
+	}
+	return nil
+}
+
+// path maps a key to a file name. Keys are hashed so they can contain any
+// characters (URLs, query strings) without escaping.
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// metaPath maps a key to the file holding its validators.
+func (c *Cache) metaPath(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".meta")
+}
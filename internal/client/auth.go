@@ -0,0 +1,62 @@
+package client
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+// Auth mode constants select how requests are authenticated.
+const (
+	AuthModeServiceAccount = "service_account"
+	AuthModeAPISecret      = "api_secret"
+)
+
+// Auth holds the credentials used to build the Authorization header.
+type Auth struct {
+	Mode     string
+	Username string
+	Secret   string
+}
+
+// ServiceAccountAuth returns Auth for a Mixpanel service account, sent as
+// Basic auth with "username:secret".
+func ServiceAccountAuth(username, secret string) Auth {
+	return Auth{Mode: AuthModeServiceAccount, Username: username, Secret: secret}
+}
+
+// APISecretAuth returns Auth for a project API secret, sent as Basic auth
+// with the secret as the username and an empty password.
+func APISecretAuth(secret string) Auth {
+	return Auth{Mode: AuthModeAPISecret, Secret: secret}
+}
+
+// ValidAuthMode reports whether m is a recognized auth mode string.
+func ValidAuthMode(m string) bool {
+	return m == AuthModeServiceAccount || m == AuthModeAPISecret
+}
+
+// validate checks that the credentials required by the mode are present.
+func (a Auth) validate() error {
+	switch a.Mode {
+	case AuthModeServiceAccount:
+		if a.Username == "" || a.Secret == "" {
+			return fmt.Errorf("service_account and service_secret must be configured; run: mp config set service_account <value>")
+		}
+	case AuthModeAPISecret:
+		if a.Secret == "" {
+			return fmt.Errorf("api_secret must be configured; run: mp config set api_secret <value>")
+		}
+	default:
+		return fmt.Errorf("invalid auth mode %q; must be one of: service_account, api_secret", a.Mode)
+	}
+	return nil
+}
+
+// header returns the value of the Authorization header.
+func (a Auth) header() string {
+	creds := a.Username + ":" + a.Secret
+	if a.Mode == AuthModeAPISecret {
+		creds = a.Secret + ":"
+	}
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(creds))
+}
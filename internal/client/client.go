@@ -1,66 +1,217 @@
 package client
 
 import (
+	"bytes"
 	"compress/gzip"
-	"encoding/base64"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
 	"fmt"
 	"io"
 	"math"
+	"math/rand/v2"
 	"net/http"
 	"net/url"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
 const (
 	maxRetries     = 1
 	baseBackoffSec = 1
+
+	// Connection pool settings. mp talks to a handful of hosts per
+	// invocation, so keep enough idle connections per host for concurrent
+	// pagination and export requests to reuse them.
+	maxIdleConns        = 64
+	maxIdleConnsPerHost = 16
+	idleConnTimeout     = 90 * time.Second
+
+	// DefaultRequestsPerSecond paces requests to Mixpanel's documented raw
+	// export limit of 3 queries per second, with bursts of up to 5 matching
+	// the Query API's concurrent-query limit. It does not enforce hourly quotas.
+	DefaultRequestsPerSecond = 3
+	rateLimitBurst           = 5
 )
 
 // Client is an authenticated HTTP client for the Mixpanel API.
 type Client struct {
 	httpClient *http.Client
-	auth       string // base64-encoded "user:secret"
-	region     string // us, eu, in
+	transport  *http.Transport // httpClient's transport, for TLS settings
+	auth       string          // Authorization header value
+	region     string          // us, eu, in
 	projectID  string
-	debug      bool
+	verbosity  int // 0 = silent, 1 = request/response summaries, 2 = also headers
+	jitter     bool
+	limiter    *rateLimiter // nil means unlimited
+	logWriter  io.Writer    // destination for debug logging
+
+	// Retry counters, updated atomically since commands may share a client
+	// across goroutines.
+	retries      atomic.Int64
+	rateLimited  atomic.Int64
+	serverErrors atomic.Int64
+}
+
+// RetryStats summarizes the transient failures a Client has seen.
+type RetryStats struct {
+	Retries      int // requests that were re-sent
+	RateLimited  int // HTTP 429 responses
+	ServerErrors int // HTTP 5xx responses
 }
 
-// New creates a Client. serviceAccount and serviceSecret are used for Basic Auth.
-// region must be one of "us", "eu", "in". debug enables request/response logging.
-func New(serviceAccount, serviceSecret, region, projectID string, debug bool) (*Client, error) {
+// RetryStats returns the transient failures seen so far.
+func (c *Client) RetryStats() RetryStats {
+	return RetryStats{
+		Retries:      int(c.retries.Load()),
+		RateLimited:  int(c.rateLimited.Load()),
+		ServerErrors: int(c.serverErrors.Load()),
+	}
+}
+
+// New creates a Client. auth supplies the credentials for Basic Auth.
+// region must be one of "us", "eu", "in". verbosity controls request/response
+// logging, which goes to stderr unless SetLogWriter is called: 1 logs method,
+// URL, status, and retries; 2 also logs headers with credentials redacted.
+func New(auth Auth, region, projectID string, verbosity int) (*Client, error) {
 	if !ValidRegion(region) {
 		return nil, fmt.Errorf("invalid region %q; must be one of: us, eu, in", region)
 	}
-	if serviceAccount == "" || serviceSecret == "" {
-		return nil, fmt.Errorf("service_account and service_secret must be configured; run: mp config set service_account <value>")
+	if err := auth.validate(); err != nil {
+		return nil, err
 	}
 
-	auth := base64.StdEncoding.EncodeToString([]byte(serviceAccount + ":" + serviceSecret))
-
+	transport := newTransport()
 	return &Client{
-		httpClient: &http.Client{Timeout: 120 * time.Second},
-		auth:       auth,
+		httpClient: &http.Client{Timeout: DefaultTimeout, Transport: transport},
+		transport:  transport,
+		auth:       auth.header(),
 		region:     region,
 		projectID:  projectID,
-		debug:      debug,
+		verbosity:  verbosity,
+		jitter:     true,
+		limiter:    newRateLimiter(DefaultRequestsPerSecond, rateLimitBurst),
+		logWriter:  os.Stderr,
 	}, nil
 }
 
+// newTransport returns an http.Transport tuned for connection reuse. It is
+// cloned from http.DefaultTransport so proxy settings (HTTPS_PROXY etc.) and
+// dial/TLS timeouts are preserved.
+func newTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = maxIdleConns
+	t.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	t.IdleConnTimeout = idleConnTimeout
+	return t
+}
+
+// SetRetryJitter enables or disables randomized retry backoff. Jitter is
+// enabled by default.
+func (c *Client) SetRetryJitter(enabled bool) {
+	c.jitter = enabled
+}
+
+// SetInsecureSkipVerify disables TLS certificate verification when skip is
+// true. It exists for test environments behind intercepting proxies and makes
+// connections vulnerable to interception.
+func (c *Client) SetInsecureSkipVerify(skip bool) {
+	if c.transport.TLSClientConfig == nil {
+		c.transport.TLSClientConfig = &tls.Config{}
+	}
+	c.transport.TLSClientConfig.InsecureSkipVerify = skip
+}
+
+// AddRootCAs trusts the PEM-encoded CA certificates in pemData in addition to
+// the system roots, e.g. the CA of a TLS-inspecting corporate proxy. It
+// returns an error if pemData contains no certificates.
+func (c *Client) AddRootCAs(pemData []byte) error {
+	if c.transport.TLSClientConfig == nil {
+		c.transport.TLSClientConfig = &tls.Config{}
+	}
+	pool := c.transport.TLSClientConfig.RootCAs
+	if pool == nil {
+		var err error
+		if pool, err = x509.SystemCertPool(); err != nil {
+			pool = x509.NewCertPool()
+		}
+	}
+	if !pool.AppendCertsFromPEM(pemData) {
+		return fmt.Errorf("no PEM certificates found")
+	}
+	c.transport.TLSClientConfig.RootCAs = pool
+	return nil
+}
+
+// SetRateLimit paces outgoing requests to rps requests per second, including
+// retries. A value of zero or less disables pacing.
+func (c *Client) SetRateLimit(rps float64) {
+	if rps <= 0 {
+		c.limiter = nil
+		return
+	}
+	c.limiter = newRateLimiter(rps, rateLimitBurst)
+}
+
+// DefaultTimeout bounds each request, including reading its response body,
+// unless SetTimeout changes it.
+const DefaultTimeout = 120 * time.Second
+
+// SetTimeout limits how long each request may take, including reading its
+// response body. A timeout of 0 disables the limit, e.g. for long exports.
+func (c *Client) SetTimeout(d time.Duration) {
+	c.httpClient.Timeout = max(d, 0)
+}
+
+// SetLogWriter sends debug logging to w instead of stderr. A nil w restores
+// stderr. Logging still only happens when verbosity is above zero.
+func (c *Client) SetLogWriter(w io.Writer) {
+	if w == nil {
+		w = os.Stderr
+	}
+	c.logWriter = w
+}
+
 // Get performs an authenticated GET request against the given API family and path.
 // params are appended as query parameters.
 func (c *Client) Get(apiFamily, path string, params url.Values) (*http.Response, error) {
-	return c.do(http.MethodGet, apiFamily, path, params, nil)
+	return c.do(http.MethodGet, apiFamily, path, params, nil, "", nil)
+}
+
+// GetWithHeader is like Get but also sends the headers in h, e.g. the
+// validators of a conditional request.
+func (c *Client) GetWithHeader(apiFamily, path string, params url.Values, h http.Header) (*http.Response, error) {
+	return c.do(http.MethodGet, apiFamily, path, params, nil, "", h)
 }
 
 // Post performs an authenticated POST request with form-encoded params as the body.
 func (c *Client) Post(apiFamily, path string, params url.Values) (*http.Response, error) {
-	return c.do(http.MethodPost, apiFamily, path, nil, params)
+	return c.PostWithHeader(apiFamily, path, params, nil)
+}
+
+// PostWithHeader is like Post but also sends the headers in h.
+func (c *Client) PostWithHeader(apiFamily, path string, params url.Values, h http.Header) (*http.Response, error) {
+	if len(params) == 0 {
+		return c.do(http.MethodPost, apiFamily, path, nil, nil, "", h)
+	}
+	return c.do(http.MethodPost, apiFamily, path, nil, []byte(params.Encode()), "application/x-www-form-urlencoded", h)
 }
 
-func (c *Client) do(method, apiFamily, path string, query url.Values, form url.Values) (*http.Response, error) {
+// PostJSON performs an authenticated POST request with payload encoded as a
+// JSON body. query is appended as query parameters.
+func (c *Client) PostJSON(apiFamily, path string, query url.Values, payload any) (*http.Response, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("encoding request body: %w", err)
+	}
+	return c.do(http.MethodPost, apiFamily, path, query, encoded, "application/json", nil)
+}
+
+func (c *Client) do(method, apiFamily, path string, query url.Values, payload []byte, contentType string, header http.Header) (*http.Response, error) {
 	base, err := ResolveURL(apiFamily, c.region)
 	if err != nil {
 		return nil, err
@@ -71,29 +222,37 @@ func (c *Client) do(method, apiFamily, path string, query url.Values, form url.V
 		fullURL += "?" + query.Encode()
 	}
 
-	var body io.Reader
-	var contentType string
-	if form != nil && len(form) > 0 {
-		encoded := form.Encode()
-		body = stringReader(encoded)
-		contentType = "application/x-www-form-urlencoded"
-	}
-
 	var resp *http.Response
 	for attempt := 0; attempt <= maxRetries; attempt++ {
+		// A fresh body reader per attempt so POST retries resend the payload.
+		var body io.Reader
+		if payload != nil {
+			body = bytes.NewReader(payload)
+		}
+
 		req, err := http.NewRequest(method, fullURL, body)
 		if err != nil {
 			return nil, fmt.Errorf("creating request: %w", err)
 		}
 
-		req.Header.Set("Authorization", "Basic "+c.auth)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		req.Header.Set("Authorization", c.auth)
 		req.Header.Set("Accept-Encoding", "gzip")
 		req.Header.Set("Accept", "application/json")
 		if contentType != "" {
 			req.Header.Set("Content-Type", contentType)
 		}
 
+		if c.limiter != nil {
+			if err := c.limiter.Wait(req.Context()); err != nil {
+				return nil, fmt.Errorf("waiting for rate limiter: %w", err)
+			}
+		}
+
 		c.debugf("--> %s %s\n", method, fullURL)
+		c.debugHeaders(">  ", req.Header)
 
 		resp, err = c.httpClient.Do(req)
 		if err != nil {
@@ -101,21 +260,32 @@ func (c *Client) do(method, apiFamily, path string, query url.Values, form url.V
 		}
 
 		c.debugf("<-- %d %s\n", resp.StatusCode, resp.Status)
+		c.debugHeaders("<  ", resp.Header)
 
-		if resp.StatusCode != http.StatusTooManyRequests {
+		// Rate limits are always retried; server errors only for GET, which
+		// is safe to repeat.
+		var reason string
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests:
+			c.rateLimited.Add(1)
+			reason = "rate limited"
+		case resp.StatusCode >= 500:
+			c.serverErrors.Add(1)
+			if method == http.MethodGet {
+				reason = "server error"
+			}
+		}
+		if reason == "" {
 			break
 		}
 
-		// Rate limited: back off and retry.
+		// Back off and retry.
 		if attempt < maxRetries {
-			wait := backoff(attempt, resp)
-			c.debugf("    rate limited, retrying in %v\n", wait)
-			resp.Body.Close()
+			wait := backoff(attempt, resp, c.jitter)
+			c.debugf("    %s, retrying in %v\n", reason, wait)
+			c.retries.Add(1)
+			drainAndClose(resp.Body)
 			time.Sleep(wait)
-			// Reset body reader for POST retries.
-			if form != nil && len(form) > 0 {
-				body = stringReader(form.Encode())
-			}
 		}
 	}
 
@@ -132,6 +302,13 @@ func (c *Client) do(method, apiFamily, path string, query url.Values, form url.V
 	return resp, nil
 }
 
+// drainAndClose discards any unread body so the underlying connection can be
+// returned to the pool, then closes it.
+func drainAndClose(body io.ReadCloser) {
+	_, _ = io.Copy(io.Discard, body)
+	body.Close()
+}
+
 // gzipReadCloser wraps a gzip reader so that closing it also closes the underlying body.
 type gzipReadCloser struct {
 	*gzip.Reader
@@ -143,40 +320,81 @@ func (g gzipReadCloser) Close() error {
 	return g.underlying.Close()
 }
 
-// backoff calculates the wait duration after a 429 response.
+// backoff calculates the wait duration after a 429 or retried 5xx response.
 // It uses the Retry-After header if present, otherwise exponential backoff.
-func backoff(attempt int, resp *http.Response) time.Duration {
+// When jitter is true the exponential delay is replaced by a random duration
+// between zero and that delay ("full jitter"); Retry-After is always honored
+// exactly. The jitter uses math/rand/v2's top-level source, which is seeded
+// per process and safe for clients shared across goroutines.
+func backoff(attempt int, resp *http.Response, jitter bool) time.Duration {
 	if ra := resp.Header.Get("Retry-After"); ra != "" {
-		if secs, err := strconv.Atoi(ra); err == nil && secs > 0 {
-			return time.Duration(secs) * time.Second
+		if wait, ok := parseRetryAfter(ra, time.Now()); ok {
+			return wait
 		}
 	}
-	return time.Duration(math.Pow(2, float64(attempt))*baseBackoffSec) * time.Second
+	wait := time.Duration(math.Pow(2, float64(attempt))*baseBackoffSec) * time.Second
+	if jitter {
+		wait = time.Duration(rand.Int64N(int64(wait) + 1))
+	}
+	return wait
 }
 
-func (c *Client) debugf(format string, a ...any) {
-	if c.debug {
-		fmt.Fprintf(os.Stderr, "[mp debug] "+format, a...)
+// parseRetryAfter interprets a Retry-After header value, which may be either
+// a number of seconds or an HTTP-date. Dates are converted to the delay from
+// now, clamped to zero if already past.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs <= 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
 	}
+	if t, err := http.ParseTime(value); err == nil {
+		wait := t.Sub(now)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
 }
 
-// ProjectID returns the configured project ID.
-func (c *Client) ProjectID() string {
-	return c.projectID
+func (c *Client) debugf(format string, a ...any) {
+	if c.verbosity > 0 {
+		fmt.Fprintf(c.logWriter, "[mp debug] "+format, a...)
+	}
 }
 
-// stringReader creates an io.Reader from a string.
-type stringReaderType struct{ s string }
-
-func (r *stringReaderType) Read(p []byte) (int, error) {
-	n := copy(p, r.s)
-	r.s = r.s[n:]
-	if len(r.s) == 0 {
-		return n, io.EOF
+// debugHeaders logs h at verbosity 2 and above, one header per line in sorted
+// order. Credential-bearing headers are redacted.
+func (c *Client) debugHeaders(prefix string, h http.Header) {
+	if c.verbosity < 2 {
+		return
+	}
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		val := strings.Join(h[k], ", ")
+		if redactedHeaders[http.CanonicalHeaderKey(k)] {
+			val = "[REDACTED]"
+		}
+		c.debugf("%s%s: %s\n", prefix, k, val)
 	}
-	return n, nil
 }
 
-func stringReader(s string) io.Reader {
-	return &stringReaderType{s: s}
+// redactedHeaders lists headers whose values must never be logged.
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// ProjectID returns the configured project ID.
+func (c *Client) ProjectID() string {
+	return c.projectID
 }
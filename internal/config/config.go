@@ -1,4 +1,5 @@
-// Package config manages persistent CLI configuration stored in ~/.config/mp/config.yaml.
+// Package config manages persistent CLI configuration stored in
+// $XDG_CONFIG_HOME/mp/config.yaml (default ~/.config/mp/config.yaml).
 // It provides read/write/list operations and masks sensitive values in output.
 package config
 
@@ -6,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/viper"
@@ -13,15 +15,30 @@ import (
 
 // Known configuration keys.
 const (
-	KeyProjectID     = "project_id"
-	KeyRegion        = "region"
+	KeyProjectID      = "project_id"
+	KeyRegion         = "region"
 	KeyServiceAccount = "service_account"
-	KeyServiceSecret = "service_secret"
+	KeyServiceSecret  = "service_secret"
+	KeyRetryJitter    = "retry_jitter"
+	KeyAuthMode       = "auth_mode"
+	KeyAPISecret      = "api_secret"
+	KeyClampPageSize  = "clamp_page_size"
+	KeyConcurrency    = "concurrency"
+	KeyRequestsPerSec = "requests_per_second"
+	KeyConditional    = "conditional_requests"
+	KeyMaxRespBytes   = "max_response_bytes"
+	KeyCACert         = "ca_cert"
+	KeyCache          = "cache"
 )
 
+// MaxConcurrency is the upper bound for the concurrency setting, chosen to
+// stay well under Mixpanel's per-project concurrent query limits.
+const MaxConcurrency = 16
+
 // sensitiveKeys are masked in list output.
 var sensitiveKeys = map[string]bool{
 	KeyServiceSecret: true,
+	KeyAPISecret:     true,
 }
 
 // knownKeys defines the valid configuration keys and their descriptions.
@@ -30,6 +47,16 @@ var knownKeys = map[string]string{
 	KeyRegion:         "API region (us, eu, in)",
 	KeyServiceAccount: "Service account username",
 	KeyServiceSecret:  "Service account secret",
+	KeyRetryJitter:    "Randomize retry backoff (true, false; default true)",
+	KeyAuthMode:       "Authentication mode (service_account, api_secret)",
+	KeyAPISecret:      "Project API secret (for auth_mode api_secret)",
+	KeyClampPageSize:  "Clamp out-of-range profiles --page-size instead of failing (true, false)",
+	KeyConcurrency:    "Maximum parallel API requests per command (1-16; default 4)",
+	KeyRequestsPerSec: "Client-side request rate limit per second (0 disables; default 3)",
+	KeyConditional:    "Revalidate expired cache entries with ETag/Last-Modified (true, false; default false)",
+	KeyMaxRespBytes:   "Largest buffered API response in bytes (0 disables; default 268435456)",
+	KeyCACert:         "PEM file of extra CA certificates to trust (added to the system roots)",
+	KeyCache:          "Cache metadata responses on disk (true, false; default false)",
 }
 
 // Config wraps viper to manage mp configuration.
@@ -38,21 +65,42 @@ type Config struct {
 	filePath string
 }
 
-// New creates a Config that reads from ~/.config/mp/config.yaml.
+// Path returns the location of the configuration file. MP_CONFIG_FILE takes
+// precedence when set; otherwise the file is config.yaml in the mp directory
+// under $XDG_CONFIG_HOME, falling back to ~/.config when XDG_CONFIG_HOME is
+// unset.
+func Path() (string, error) {
+	if p := os.Getenv("MP_CONFIG_FILE"); p != "" {
+		return p, nil
+	}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "mp", "config.yaml"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("determining home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "mp", "config.yaml"), nil
+}
+
+// New creates a Config that reads from the file returned by Path.
 // It creates the config directory if it does not exist.
 func New() (*Config, error) {
-	home, err := os.UserHomeDir()
+	filePath, err := Path()
 	if err != nil {
-		return nil, fmt.Errorf("determining home directory: %w", err)
+		return nil, err
 	}
+	return Open(filePath)
+}
 
-	dir := filepath.Join(home, ".config", "mp")
+// Open creates a Config that reads from and writes to filePath.
+// It creates the file's directory if it does not exist.
+func Open(filePath string) (*Config, error) {
+	dir := filepath.Dir(filePath)
 	if err := os.MkdirAll(dir, 0o700); err != nil {
 		return nil, fmt.Errorf("creating config directory %s: %w", dir, err)
 	}
 
-	filePath := filepath.Join(dir, "config.yaml")
-
 	v := viper.New()
 	v.SetConfigFile(filePath)
 	v.SetConfigType("yaml")
@@ -81,15 +129,62 @@ func (c *Config) Set(key, value string) error {
 		return fmt.Errorf("unknown config key %q; valid keys: %s", key, strings.Join(KnownKeyNames(), ", "))
 	}
 
+	value, err := ValidateValue(key, value)
+	if err != nil {
+		return err
+	}
+
+	c.v.Set(key, value)
+	return c.write()
+}
+
+// ValidateValue checks value for a known key and returns it normalized, e.g.
+// lowercased regions and canonical booleans.
+func ValidateValue(key, value string) (string, error) {
 	if key == KeyRegion {
 		value = strings.ToLower(value)
 		if value != "us" && value != "eu" && value != "in" {
-			return fmt.Errorf("invalid region %q; must be one of: us, eu, in", value)
+			return "", fmt.Errorf("invalid region %q; must be one of: us, eu, in", value)
 		}
 	}
 
-	c.v.Set(key, value)
-	return c.write()
+	if key == KeyAuthMode {
+		value = strings.ToLower(value)
+		if value != "service_account" && value != "api_secret" {
+			return "", fmt.Errorf("invalid auth_mode %q; must be one of: service_account, api_secret", value)
+		}
+	}
+
+	if key == KeyRetryJitter || key == KeyClampPageSize || key == KeyConditional || key == KeyCache {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid %s %q; must be true or false", key, value)
+		}
+		value = strconv.FormatBool(b)
+	}
+
+	if key == KeyConcurrency {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > MaxConcurrency {
+			return "", fmt.Errorf("invalid %s %q; must be an integer from 1 to %d", key, value, MaxConcurrency)
+		}
+	}
+
+	if key == KeyRequestsPerSec {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil || f < 0 {
+			return "", fmt.Errorf("invalid %s %q; must be a non-negative number", key, value)
+		}
+	}
+
+	if key == KeyMaxRespBytes {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("invalid %s %q; must be a non-negative integer", key, value)
+		}
+	}
+
+	return value, nil
 }
 
 // List returns all set configuration entries as key-value pairs.
@@ -102,13 +197,18 @@ func (c *Config) List() []Entry {
 			continue
 		}
 		if sensitiveKeys[key] {
-			val = mask(val)
+			val = Mask(val)
 		}
 		entries = append(entries, Entry{Key: key, Value: val})
 	}
 	return entries
 }
 
+// IsSensitive reports whether key holds a secret that is masked in output.
+func IsSensitive(key string) bool {
+	return sensitiveKeys[key]
+}
+
 // Entry is a single configuration key-value pair.
 type Entry struct {
 	Key   string `json:"key"`
@@ -117,7 +217,7 @@ type Entry struct {
 
 // KnownKeyNames returns sorted known key names.
 func KnownKeyNames() []string {
-	return []string{KeyProjectID, KeyRegion, KeyServiceAccount, KeyServiceSecret}
+	return []string{KeyProjectID, KeyRegion, KeyAuthMode, KeyServiceAccount, KeyServiceSecret, KeyAPISecret, KeyRetryJitter, KeyClampPageSize, KeyConcurrency, KeyRequestsPerSec, KeyConditional, KeyMaxRespBytes, KeyCACert, KeyCache}
 }
 
 // FilePath returns the path to the configuration file.
@@ -129,8 +229,9 @@ func (c *Config) write() error {
 	return c.v.WriteConfigAs(c.filePath)
 }
 
-// mask shows the first 4 characters followed by "****".
-func mask(s string) string {
+// Mask redacts a secret for display, keeping its first 4 characters followed
+// by "****".
+func Mask(s string) string {
 	if len(s) <= 4 {
 		return "****"
 	}
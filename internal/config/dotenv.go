@@ -0,0 +1,100 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// envPrefix is the prefix of the environment variables mp reads.
+const envPrefix = "MP_"
+
+// LoadEnvFile reads KEY=VALUE pairs from a dotenv file at path and exports
+// the MP_-prefixed ones that are not already set in the environment, so real
+// environment variables take precedence over the file. Other keys are
+// ignored.
+//
+// Blank lines and lines starting with # are skipped, and an optional
+// "export " prefix is allowed. Values may be double-quoted (supporting \n, \t,
+// \" and \\ escapes), single-quoted (taken literally), or unquoted, in which
+// case a " #" starts a trailing comment.
+func LoadEnvFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening env file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			return fmt.Errorf("%s:%d: expected KEY=VALUE", path, n)
+		}
+		key = strings.TrimSpace(key)
+		value, err := parseEnvValue(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+
+		if !strings.HasPrefix(key, envPrefix) {
+			continue
+		}
+		if _, set := os.LookupEnv(key); set {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("setting %s: %w", key, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading env file: %w", err)
+	}
+	return nil
+}
+
+// parseEnvValue unquotes a dotenv value.
+func parseEnvValue(raw string) (string, error) {
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		var b strings.Builder
+		for i := 1; i < len(raw); i++ {
+			c := raw[i]
+			switch {
+			case c == '"':
+				return b.String(), nil
+			case c == '\\' && i+1 < len(raw):
+				i++
+				switch raw[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				default:
+					b.WriteByte(raw[i])
+				}
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return "", fmt.Errorf("unterminated double-quoted value")
+	case strings.HasPrefix(raw, "'"):
+		end := strings.IndexByte(raw[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated single-quoted value")
+		}
+		return raw[1 : 1+end], nil
+	default:
+		if i := strings.Index(raw, " #"); i >= 0 {
+			raw = raw[:i]
+		}
+		return strings.TrimSpace(raw), nil
+	}
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Problem is a single issue found by Validate.
+type Problem struct {
+	Key     string `json:"key,omitempty"`
+	Line    int    `json:"line,omitempty"` // 1-based; 0 when unknown
+	Message string `json:"message"`
+}
+
+// Validate lints the YAML config file at filePath without modifying it. It
+// reports unknown keys, values the Set validation would reject, and missing
+// credentials for the configured auth_mode. The error is non-nil only when
+// the file cannot be read or parsed.
+func Validate(filePath string) ([]Problem, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("parsing config: %w", err)
+	}
+
+	lines := keyLines(data)
+	var problems []Problem
+
+	keys := v.AllKeys()
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, ok := knownKeys[key]; !ok {
+			problems = append(problems, Problem{Key: key, Line: lines[key],
+				Message: fmt.Sprintf("unknown config key %q", key)})
+			continue
+		}
+		if _, err := ValidateValue(key, v.GetString(key)); err != nil {
+			problems = append(problems, Problem{Key: key, Line: lines[key], Message: err.Error()})
+		}
+	}
+
+	if strings.ToLower(v.GetString(KeyAuthMode)) == "api_secret" {
+		if v.GetString(KeyAPISecret) == "" {
+			problems = append(problems, Problem{Key: KeyAPISecret,
+				Message: "auth_mode api_secret requires api_secret"})
+		}
+	} else {
+		for _, key := range []string{KeyServiceAccount, KeyServiceSecret} {
+			if v.GetString(key) == "" {
+				problems = append(problems, Problem{Key: key,
+					Message: fmt.Sprintf("service account auth requires %s", key)})
+			}
+		}
+	}
+
+	return problems, nil
+}
+
+// keyLines maps each top-level YAML key to the line it is defined on.
+func keyLines(data []byte) map[string]int {
+	lines := make(map[string]int)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if line == "" || line[0] == ' ' || line[0] == '\t' || line[0] == '#' {
+			continue
+		}
+		if key, _, ok := strings.Cut(line, ":"); ok {
+			key = strings.ToLower(strings.Trim(strings.TrimSpace(key), `"'`))
+			if _, seen := lines[key]; !seen {
+				lines[key] = n
+			}
+		}
+	}
+	return lines
+}
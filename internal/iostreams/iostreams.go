@@ -8,6 +8,14 @@ import (
 	"os"
 
 	"github.com/muesli/termenv"
+	"golang.org/x/term"
+)
+
+// Terminal dimensions assumed when Out is not a terminal or its size cannot
+// be read.
+const (
+	DefaultTerminalWidth  = 120
+	DefaultTerminalHeight = 24
 )
 
 // IOStreams bundles the three standard streams together with display options.
@@ -55,6 +63,44 @@ func (s *IOStreams) IsTerminal() bool {
 	return false
 }
 
+// TerminalWidth returns the width of the terminal attached to Out in columns,
+// or DefaultTerminalWidth when Out is not a terminal.
+func (s *IOStreams) TerminalWidth() int {
+	w, _ := s.terminalSize()
+	return w
+}
+
+// TerminalHeight returns the height of the terminal attached to Out in rows,
+// or DefaultTerminalHeight when Out is not a terminal.
+func (s *IOStreams) TerminalHeight() int {
+	_, h := s.terminalSize()
+	return h
+}
+
+// terminalSize returns the size of the terminal attached to Out, using the
+// defaults for any dimension that cannot be read.
+func (s *IOStreams) terminalSize() (width, height int) {
+	width, height = DefaultTerminalWidth, DefaultTerminalHeight
+	if f, ok := s.Out.(*os.File); ok && fileIsTerminal(f) {
+		if w, h, err := term.GetSize(int(f.Fd())); err == nil {
+			if w > 0 {
+				width = w
+			}
+			if h > 0 {
+				height = h
+			}
+		}
+	}
+	return width, height
+}
+
+// CanPrompt reports whether the user can answer interactive prompts, which
+// requires both stdin and stdout to be terminals.
+func (s *IOStreams) CanPrompt() bool {
+	in, ok := s.In.(*os.File)
+	return ok && fileIsTerminal(in) && s.IsTerminal()
+}
+
 // ColorEnabled reports whether colored output should be produced.
 func (s *IOStreams) ColorEnabled() bool {
 	return s.colorEnabled
@@ -81,6 +127,16 @@ func (s *IOStreams) Errorf(format string, a ...any) {
 	fmt.Fprintf(s.ErrOut, format, a...)
 }
 
+// Footer writes a muted summary line, such as "12 cohorts", after a table.
+// It is suppressed in quiet mode and when Out is not a terminal, so piped
+// output contains only the table rows.
+func (s *IOStreams) Footer(format string, a ...any) {
+	if s.quiet || !s.IsTerminal() {
+		return
+	}
+	fmt.Fprintf(s.Out, "\n%s\n", s.Muted(fmt.Sprintf(format, a...)))
+}
+
 // --- Color helpers ---
 
 // Success returns text styled as green (success).
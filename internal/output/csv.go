@@ -5,11 +5,14 @@ import (
 	"io"
 )
 
-// PrintCSV writes headers and rows as standard CSV to w.
+// PrintCSV writes headers and rows as standard CSV to w. The header row is
+// omitted when SetNoHeader is enabled.
 func PrintCSV(w io.Writer, headers []string, rows [][]string) error {
 	cw := csv.NewWriter(w)
-	if err := cw.Write(headers); err != nil {
-		return err
+	if !noHeader {
+		if err := cw.Write(headers); err != nil {
+			return err
+		}
 	}
 	for _, row := range rows {
 		if err := cw.Write(row); err != nil {
@@ -19,3 +22,32 @@ func PrintCSV(w io.Writer, headers []string, rows [][]string) error {
 	cw.Flush()
 	return cw.Error()
 }
+
+// CSVWriter streams CSV rows one at a time.
+type CSVWriter struct {
+	cw *csv.Writer
+}
+
+// NewCSVWriter creates a CSVWriter that writes to w.
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{cw: csv.NewWriter(w)}
+}
+
+// Write encodes a single row.
+func (c *CSVWriter) Write(row []string) error {
+	return c.cw.Write(row)
+}
+
+// WriteHeader encodes the header row, unless SetNoHeader is enabled.
+func (c *CSVWriter) WriteHeader(headers []string) error {
+	if noHeader {
+		return nil
+	}
+	return c.cw.Write(headers)
+}
+
+// Flush writes any buffered rows and reports any error from earlier writes.
+func (c *CSVWriter) Flush() error {
+	c.cw.Flush()
+	return c.cw.Error()
+}
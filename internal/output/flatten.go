@@ -0,0 +1,68 @@
+package output
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// FlattenJSON flattens nested maps and arrays in v into a single map keyed by
+// dot-separated paths, e.g. {"a": {"b": [{"c": 1}]}} becomes {"a.b.0.c": 1}.
+// Empty maps and arrays are kept as values under their own path. Values that
+// are not generic JSON (structs, typed slices) are first normalized through a
+// JSON round trip. A null v yields an empty map and any other scalar is
+// returned under the empty key.
+func FlattenJSON(v any) map[string]any {
+	out := make(map[string]any)
+	if v = NormalizeJSON(v); v != nil {
+		flattenInto(out, "", v)
+	}
+	return out
+}
+
+func flattenInto(out map[string]any, prefix string, v any) {
+	switch t := v.(type) {
+	case map[string]any:
+		if len(t) == 0 && prefix != "" {
+			out[prefix] = t
+			return
+		}
+		for k, child := range t {
+			flattenInto(out, joinPath(prefix, k), child)
+		}
+	case []any:
+		if len(t) == 0 && prefix != "" {
+			out[prefix] = t
+			return
+		}
+		for i, child := range t {
+			flattenInto(out, joinPath(prefix, strconv.Itoa(i)), child)
+		}
+	default:
+		out[prefix] = t
+	}
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// NormalizeJSON converts v to the generic types produced by json.Unmarshal
+// (map[string]any, []any, float64, string, bool, nil).
+func NormalizeJSON(v any) any {
+	switch v.(type) {
+	case map[string]any, []any, string, float64, bool, nil:
+		return v
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var generic any
+	if err := json.Unmarshal(b, &generic); err != nil {
+		return v
+	}
+	return generic
+}
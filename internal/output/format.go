@@ -0,0 +1,71 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NumericValue converts a decoded JSON value to a float64. It reports false
+// for nil, non-numeric values, and strings that do not parse as numbers.
+func NumericValue(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// FormatNumber renders f without trailing zeros, e.g. "42" or "3.5".
+func FormatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// FormatPercent renders v as a percentage of total, e.g. "12.3%".
+// Non-numeric values and zero totals render as "0.0%".
+func FormatPercent(v any, total float64) string {
+	n, ok := NumericValue(v)
+	if !ok || total == 0 {
+		return "0.0%"
+	}
+	return fmt.Sprintf("%.1f%%", n/total*100)
+}
+
+// Transpose swaps the rows and columns of a table whose first column
+// holds row labels. The column headers after the first become the new row
+// labels, and corner is used as the header of the new label column.
+func Transpose(corner string, headers []string, rows [][]string) ([]string, [][]string) {
+	newHeaders := make([]string, 0, 1+len(rows))
+	newHeaders = append(newHeaders, corner)
+	for _, row := range rows {
+		label := ""
+		if len(row) > 0 {
+			label = row[0]
+		}
+		newHeaders = append(newHeaders, label)
+	}
+
+	newRows := make([][]string, 0, len(headers))
+	for j := 1; j < len(headers); j++ {
+		row := make([]string, 0, 1+len(rows))
+		row = append(row, headers[j])
+		for _, r := range rows {
+			val := ""
+			if j < len(r) {
+				val = r[j]
+			}
+			row = append(row, val)
+		}
+		newRows = append(newRows, row)
+	}
+	return newHeaders, newRows
+}
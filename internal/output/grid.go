@@ -0,0 +1,159 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Map2DOptions controls how Map2D lays out a date × series grid.
+type Map2DOptions struct {
+	// Order lists the series in column (or row) order. When nil, every
+	// series in the values is used, sorted by name.
+	Order []string
+	// SeriesHeader labels the series column when series are listed as rows,
+	// e.g. "SEGMENT" or "EVENT".
+	SeriesHeader string
+	// SeriesAsRows lists one series per row with the dates as columns,
+	// instead of one date per row with the series as columns.
+	SeriesAsRows bool
+	// SingleHeader, when set, simplifies a grid with a single series to a
+	// DATE | <SingleHeader> table with one date per row.
+	SingleHeader string
+	// Transpose swaps the rows and columns of the layout chosen above.
+	Transpose bool
+	// Percent renders each value as its share of the date's total across
+	// all series. It has no effect when there is a single series.
+	Percent bool
+	// BlankMissing leaves cells empty for dates a series has no value for,
+	// instead of showing "0", so absent data stands out from true zeros.
+	BlankMissing bool
+	// Totals appends a TOTAL row summing each series over the dates.
+	Totals bool
+	// Derived adds columns after each series' column, such as moving
+	// averages. Their TOTAL cells are left empty.
+	Derived []Map2DColumn
+}
+
+// Map2DColumn is a column computed from a series and placed after it.
+type Map2DColumn struct {
+	// Header names the column for a series.
+	Header func(series string) string
+	// Cells returns the column's cells for a series, one per date in order.
+	Cells func(series string) []string
+}
+
+// missingCell is shown for dates a series has no value for.
+const missingCell = "0"
+
+// Map2D builds the table of a grid of values keyed by series and then by
+// date, as decoded from the Mixpanel query APIs: values[series] is a
+// map[string]any from date to value. Dates appear in the order given, and
+// values a series lacks are shown as "0", or left empty with BlankMissing.
+func Map2D(dates []string, values map[string]any, opts Map2DOptions) ([]string, [][]string) {
+	order := opts.Order
+	if order == nil {
+		order = make([]string, 0, len(values))
+		for name := range values {
+			order = append(order, name)
+		}
+		sort.Strings(order)
+	}
+	single := opts.SingleHeader != "" && len(order) == 1
+
+	series := make([]map[string]any, len(order))
+	for i, name := range order {
+		series[i], _ = values[name].(map[string]any)
+	}
+
+	var dateTotals []float64
+	if opts.Percent && len(order) > 1 {
+		dateTotals = make([]float64, len(dates))
+		for _, data := range series {
+			for d, date := range dates {
+				if n, ok := NumericValue(data[date]); ok {
+					dateTotals[d] += n
+				}
+			}
+		}
+	}
+
+	derived := make([][][]string, len(opts.Derived))
+	for c, col := range opts.Derived {
+		derived[c] = make([][]string, len(order))
+		for i, name := range order {
+			derived[c][i] = col.Cells(name)
+		}
+	}
+
+	headers := make([]string, 0, 1+len(order)*(1+len(opts.Derived)))
+	headers = append(headers, "DATE")
+	for _, name := range order {
+		if single {
+			headers = append(headers, opts.SingleHeader)
+		} else {
+			headers = append(headers, name)
+		}
+		for _, col := range opts.Derived {
+			headers = append(headers, col.Header(name))
+		}
+	}
+
+	sums := make([]float64, len(order))
+	rows := make([][]string, 0, len(dates)+1)
+	for d, date := range dates {
+		row := make([]string, 0, len(headers))
+		row = append(row, date)
+		for i, data := range series {
+			v, exists := data[date]
+			val := missingCell
+			switch {
+			case !exists && opts.BlankMissing:
+				val = ""
+			case dateTotals != nil:
+				val = FormatPercent(v, dateTotals[d])
+			case exists:
+				val = fmt.Sprintf("%v", v)
+			}
+			if n, ok := NumericValue(v); ok {
+				sums[i] += n
+			}
+			row = append(row, val)
+			for c := range opts.Derived {
+				cell := ""
+				if d < len(derived[c][i]) {
+					cell = derived[c][i][d]
+				}
+				row = append(row, cell)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	if opts.Totals {
+		row := make([]string, 0, len(headers))
+		row = append(row, "TOTAL")
+		for _, sum := range sums {
+			row = append(row, FormatNumber(sum))
+			for range opts.Derived {
+				row = append(row, "")
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	if (opts.SeriesAsRows && !single) != opts.Transpose {
+		corner := opts.SeriesHeader
+		if single {
+			corner = "DATE"
+		}
+		headers, rows = Transpose(corner, headers, rows)
+	}
+	return headers, rows
+}
+
+// RenderMap2D writes the Map2D table of values to w with PrintTable.
+func RenderMap2D(w io.Writer, dates []string, values map[string]any, opts Map2DOptions, isTTY bool) {
+	headers, rows := Map2D(dates, values, opts)
+	PrintTable(w, headers, rows, isTTY)
+}
@@ -11,14 +11,35 @@ import (
 	"github.com/itchyny/gojq"
 )
 
-// PrintJSON pretty-prints v as indented JSON to w.
+// compactJSON makes PrintJSON write single-line JSON.
+var compactJSON bool
+
+// SetCompactJSON switches PrintJSON between indented output, the default, and
+// compact output with one line per document, which is smaller and faster to
+// pipe.
+func SetCompactJSON(enabled bool) {
+	compactJSON = enabled
+}
+
+// PrintJSON writes v as JSON to w, pretty-printed with a two-space indent
+// unless compact output was selected with SetCompactJSON.
 func PrintJSON(w io.Writer, v any) error {
+	if compactJSON {
+		return PrintJSONCompact(w, v)
+	}
 	enc := json.NewEncoder(w)
 	enc.SetIndent("", "  ")
 	enc.SetEscapeHTML(false)
 	return enc.Encode(v)
 }
 
+// PrintJSONCompact writes v to w as JSON on a single line.
+func PrintJSONCompact(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
 // FilterFields takes a slice of maps and returns a new slice containing only
 // the specified fields from each map.
 func FilterFields(data []map[string]any, fields []string) []map[string]any {
@@ -76,8 +97,10 @@ func ApplyJQ(w io.Writer, data any, expr string) error {
 }
 
 // ApplyTemplate renders data through a Go text/template and writes to w.
+// Besides the standard template functions, templates can use json,
+// jsonIndent, keys, pick, join, list, dict, isList, isMap, and brief.
 func ApplyTemplate(w io.Writer, data any, tmpl string) error {
-	t, err := template.New("").Parse(tmpl)
+	t, err := template.New("").Funcs(templateFuncs).Parse(tmpl)
 	if err != nil {
 		return fmt.Errorf("parsing template: %w", err)
 	}
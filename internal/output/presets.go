@@ -0,0 +1,185 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// builtinPresets are the templates selectable with --template-preset. They
+// expect the generic JSON form of the output (see NormalizeJSON), so keys are
+// the ones --json prints.
+var builtinPresets = map[string]string{
+	// summary prints one line: the record count of a list, or the top-level
+	// keys of an object with nested values abbreviated.
+	"summary": `{{- if isList . -}}
+{{ len . }} records
+{{ else if isMap . -}}
+{{ range $i, $k := keys . }}{{ if $i }} {{ end }}{{ $k }}={{ brief (index $ $k) }}{{ end }}
+{{ else -}}
+{{ brief . }}
+{{ end -}}`,
+
+	// slack prints a Slack incoming-webhook payload with the output as a
+	// code block, ready for curl -d @- to the webhook URL.
+	"slack": `{{ json (dict "text" (printf "` + "```" + `\n%s\n` + "```" + `" (jsonIndent .))) }}
+`,
+
+	// tsv prints a list of objects, or a single object, as tab-separated
+	// values with a header row of the first object's keys.
+	"tsv": `{{- $rows := . }}{{ if isMap . }}{{ $rows = list . }}{{ end -}}
+{{- if and (isList $rows) $rows -}}
+{{ $cols := keys (index $rows 0) }}{{ join $cols "\t" }}
+{{ range $rows }}{{ join (pick . $cols) "\t" }}
+{{ end }}{{ end -}}`,
+}
+
+// templateFuncs are the functions available to --template and presets.
+var templateFuncs = template.FuncMap{
+	"json":       templateJSON,
+	"jsonIndent": templateJSONIndent,
+	"keys":       templateKeys,
+	"pick":       templatePick,
+	"join":       templateJoin,
+	"list":       func(items ...any) []any { return items },
+	"dict":       templateDict,
+	"isList":     func(v any) bool { _, ok := v.([]any); return ok },
+	"isMap":      func(v any) bool { _, ok := v.(map[string]any); return ok },
+	"brief":      templateBrief,
+}
+
+// TemplatePresetNames returns the names of the built-in template presets in
+// sorted order.
+func TemplatePresetNames() []string {
+	names := make([]string, 0, len(builtinPresets))
+	for name := range builtinPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TemplatePreset returns the template of the preset name. A file
+// <name>.tmpl in userDir takes precedence over a built-in preset of the same
+// name.
+func TemplatePreset(name, userDir string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid template preset name %q", name)
+	}
+	if userDir != "" {
+		data, err := os.ReadFile(filepath.Join(userDir, name+".tmpl"))
+		if err == nil {
+			return string(data), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("reading template preset: %w", err)
+		}
+	}
+	if tmpl, ok := builtinPresets[name]; ok {
+		return tmpl, nil
+	}
+	return "", fmt.Errorf("unknown template preset %q; built-in presets: %s (or add %s)",
+		name, strings.Join(TemplatePresetNames(), ", "), filepath.Join(userDir, name+".tmpl"))
+}
+
+func templateJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+func templateJSONIndent(v any) (string, error) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	return string(b), err
+}
+
+// templateKeys returns the keys of an object in sorted order.
+func templateKeys(v any) []string {
+	m, _ := v.(map[string]any)
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// templatePick returns the values of keys in an object, nil where missing.
+func templatePick(v any, keys []string) []any {
+	m, _ := v.(map[string]any)
+	values := make([]any, len(keys))
+	for i, k := range keys {
+		values[i] = m[k]
+	}
+	return values
+}
+
+// templateJoin joins the cells of a list with sep. Strings and numbers are
+// printed plainly, null as an empty cell, and objects and lists as JSON.
+func templateJoin(v any, sep string) (string, error) {
+	var cells []string
+	switch items := v.(type) {
+	case []string:
+		cells = items
+	case []any:
+		cells = make([]string, len(items))
+		for i, item := range items {
+			cell, err := templateCell(item)
+			if err != nil {
+				return "", err
+			}
+			cells[i] = cell
+		}
+	default:
+		return "", fmt.Errorf("join: expected a list, got %T", v)
+	}
+	return strings.Join(cells, sep), nil
+}
+
+func templateCell(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		return templateJSON(v)
+	}
+}
+
+// templateDict builds an object from alternating keys and values.
+func templateDict(pairs ...any) (map[string]any, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict: odd number of arguments")
+	}
+	m := make(map[string]any, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict: key %v is not a string", pairs[i])
+		}
+		m[key] = pairs[i+1]
+	}
+	return m, nil
+}
+
+// templateBrief prints scalars plainly and abbreviates lists and objects to
+// their size.
+func templateBrief(v any) (string, error) {
+	switch v := v.(type) {
+	case []any:
+		return fmt.Sprintf("[%d items]", len(v)), nil
+	case map[string]any:
+		return fmt.Sprintf("{%d keys}", len(v)), nil
+	default:
+		return templateCell(v)
+	}
+}
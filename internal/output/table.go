@@ -3,6 +3,7 @@ package output
 import (
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 
 	"github.com/olekukonko/tablewriter"
@@ -10,6 +11,34 @@ import (
 	"github.com/olekukonko/tablewriter/tw"
 )
 
+// humanizeNumbers enables thousands separators in numeric table columns.
+var humanizeNumbers bool
+
+// SetHumanizeNumbers enables or disables comma thousands separators in the
+// numeric columns of terminal tables. TSV output is never affected.
+func SetHumanizeNumbers(enabled bool) {
+	humanizeNumbers = enabled
+}
+
+// tableWidth is the maximum width of terminal tables; 0 means unlimited.
+var tableWidth int
+
+// SetTableWidth limits terminal tables to width columns, usually the width of
+// the terminal. Wider tables shrink their widest columns and wrap cell text.
+// A width of 0 or less removes the limit. TSV output is never affected.
+func SetTableWidth(width int) {
+	tableWidth = max(width, 0)
+}
+
+// noHeader suppresses header rows in table, TSV, and CSV output.
+var noHeader bool
+
+// SetNoHeader enables or disables omitting the header row from tables, TSV,
+// and CSV, e.g. when appending runs to one file. JSON output is unaffected.
+func SetNoHeader(enabled bool) {
+	noHeader = enabled
+}
+
 // PrintTable writes tabular data. When isTTY is true it renders aligned columns
 // with a header. When false it outputs tab-separated values for piping.
 func PrintTable(w io.Writer, headers []string, rows [][]string, isTTY bool) {
@@ -18,9 +47,11 @@ func PrintTable(w io.Writer, headers []string, rows [][]string, isTTY bool) {
 		return
 	}
 
+	aligns := columnAlignments(len(headers), rows)
 	table := tablewriter.NewTable(w,
-		tablewriter.WithHeaderAlignment(tw.AlignLeft),
-		tablewriter.WithRowAlignment(tw.AlignLeft),
+		tablewriter.WithHeaderAlignmentConfig(tw.CellAlignment{Global: tw.AlignLeft, PerColumn: aligns}),
+		tablewriter.WithRowAlignmentConfig(tw.CellAlignment{Global: tw.AlignLeft, PerColumn: aligns}),
+		tablewriter.WithMaxWidth(tableWidth),
 		tablewriter.WithRenderer(renderer.NewBlueprint(tw.Rendition{
 			Borders: tw.BorderNone,
 			Settings: tw.Settings{
@@ -32,16 +63,111 @@ func PrintTable(w io.Writer, headers []string, rows [][]string, isTTY bool) {
 		})),
 	)
 
-	table.Header(toAny(headers)...)
+	if !noHeader {
+		table.Header(toAny(headers)...)
+	}
 	for _, row := range rows {
+		if humanizeNumbers {
+			row = humanizeRow(row, aligns)
+		}
 		table.Append(toAny(row)...)
 	}
 	table.Render()
 }
 
+// columnAlignments right-aligns columns whose non-empty cells are all numeric
+// and left-aligns the rest. Columns with no non-empty cells stay left-aligned.
+func columnAlignments(n int, rows [][]string) []tw.Align {
+	aligns := make([]tw.Align, n)
+	for col := range aligns {
+		aligns[col] = tw.AlignLeft
+		seen := false
+		numeric := true
+		for _, row := range rows {
+			if col >= len(row) || row[col] == "" {
+				continue
+			}
+			seen = true
+			if !isNumericCell(row[col]) {
+				numeric = false
+				break
+			}
+		}
+		if seen && numeric {
+			aligns[col] = tw.AlignRight
+		}
+	}
+	return aligns
+}
+
+// isNumericCell reports whether a rendered cell is a number, allowing a
+// trailing percent sign and comma thousands separators.
+func isNumericCell(cell string) bool {
+	cell = strings.TrimSuffix(strings.TrimSpace(cell), "%")
+	cell = strings.ReplaceAll(cell, ",", "")
+	// ParseFloat also accepts "Inf", "NaN", and hex floats, which are text here.
+	if cell == "" || strings.ContainsAny(cell, "InfinityNaNxXpP") {
+		return false
+	}
+	_, err := strconv.ParseFloat(cell, 64)
+	return err == nil
+}
+
+// humanizeRow returns a copy of row with thousands separators added to the
+// cells of right-aligned (numeric) columns.
+func humanizeRow(row []string, aligns []tw.Align) []string {
+	out := make([]string, len(row))
+	for i, cell := range row {
+		if i < len(aligns) && aligns[i] == tw.AlignRight {
+			cell = GroupThousands(cell)
+		}
+		out[i] = cell
+	}
+	return out
+}
+
+// GroupThousands inserts comma separators into the integer part of a numeric
+// string, e.g. "1234567.89" becomes "1,234,567.89". A leading sign, decimal
+// part, exponent, and trailing percent sign are preserved. Strings that are
+// not plain numbers, or already contain commas, are returned unchanged.
+func GroupThousands(s string) string {
+	if strings.Contains(s, ",") {
+		return s
+	}
+	start := 0
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		start = 1
+	}
+	end := start
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	digits := s[start:end]
+	if len(digits) <= 3 {
+		return s
+	}
+
+	var b strings.Builder
+	b.WriteString(s[:start])
+	first := len(digits) % 3
+	if first > 0 {
+		b.WriteString(digits[:first])
+	}
+	for i := first; i < len(digits); i += 3 {
+		if b.Len() > start {
+			b.WriteByte(',')
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	b.WriteString(s[end:])
+	return b.String()
+}
+
 // printTSV writes headers and rows as tab-separated values.
 func printTSV(w io.Writer, headers []string, rows [][]string) {
-	fmt.Fprintln(w, strings.Join(headers, "\t"))
+	if !noHeader {
+		fmt.Fprintln(w, strings.Join(headers, "\t"))
+	}
 	for _, row := range rows {
 		fmt.Fprintln(w, strings.Join(row, "\t"))
 	}
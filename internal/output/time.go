@@ -0,0 +1,55 @@
+package output
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeLayout is the layout used for formatted timestamps in table and CSV output.
+const TimeLayout = "2006-01-02 15:04:05"
+
+// millisThreshold separates epoch seconds from epoch milliseconds. Seconds
+// values stay below it until the year 5138, while millisecond values for any
+// date after March 1973 are above it.
+const millisThreshold = 1e11
+
+// FormatEpoch formats an epoch timestamp in loc using TimeLayout. v may be a
+// float64, int, int64, or numeric string holding seconds or milliseconds;
+// milliseconds are detected by magnitude. Non-numeric strings are returned
+// unchanged, nil yields "", and a nil loc means UTC.
+func FormatEpoch(v any, loc *time.Location) string {
+	var epoch float64
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		epoch = t
+	case int:
+		epoch = float64(t)
+	case int64:
+		epoch = float64(t)
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(t), 64)
+		if err != nil {
+			return t
+		}
+		epoch = f
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+
+	if loc == nil {
+		loc = time.UTC
+	}
+	return EpochTime(epoch).In(loc).Format(TimeLayout)
+}
+
+// EpochTime converts an epoch value in seconds or milliseconds to a time.Time.
+func EpochTime(epoch float64) time.Time {
+	if epoch >= millisThreshold || epoch <= -millisThreshold {
+		return time.UnixMilli(int64(epoch))
+	}
+	return time.Unix(int64(epoch), 0)
+}
@@ -0,0 +1,26 @@
+package prompt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Confirm asks a yes/no question on out and reads the answer from in. Only
+// "y" or "yes", in any case, count as yes; an empty line or the end of input
+// is no.
+func Confirm(in io.Reader, out io.Writer, message string) (bool, error) {
+	fmt.Fprintf(out, "? %s [y/N] ", message)
+
+	scanner := bufio.NewScanner(in)
+	if !scanner.Scan() {
+		fmt.Fprintln(out)
+		return false, scanner.Err()
+	}
+	switch strings.ToLower(strings.TrimSpace(scanner.Text())) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
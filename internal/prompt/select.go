@@ -0,0 +1,127 @@
+// Package prompt implements minimal line-based interactive prompts. Input and
+// output are plain io.Reader/io.Writer values so prompts work with any
+// terminal and can be driven from scripted input.
+package prompt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxShown caps how many options Select lists at once. Typing a filter
+// narrows the list when there are more.
+const maxShown = 15
+
+// ErrNoSelection is returned when input ends before an option is chosen.
+var ErrNoSelection = errors.New("no selection made")
+
+// Option is a selectable item. Label is shown and matched against the filter;
+// Value is what the caller uses.
+type Option struct {
+	Label string
+	Value string
+}
+
+// Select lists options on out and reads lines from in until one is chosen.
+// A line that is a number picks that entry of the current list; any other
+// line filters the options with Filter. An empty line picks the only match
+// when exactly one option is listed.
+func Select(in io.Reader, out io.Writer, message string, options []Option) (Option, error) {
+	if len(options) == 0 {
+		return Option{}, errors.New("nothing to select from")
+	}
+
+	scanner := bufio.NewScanner(in)
+	shown := options
+	for {
+		fmt.Fprintf(out, "? %s\n", message)
+		for i, opt := range shown {
+			if i == maxShown {
+				fmt.Fprintf(out, "  ... %d more; type to filter\n", len(shown)-maxShown)
+				break
+			}
+			fmt.Fprintf(out, "  %2d) %s\n", i+1, opt.Label)
+		}
+		fmt.Fprint(out, "Number or filter: ")
+
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			if err := scanner.Err(); err != nil {
+				return Option{}, err
+			}
+			return Option{}, ErrNoSelection
+		}
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" {
+			if len(shown) == 1 {
+				return shown[0], nil
+			}
+			continue
+		}
+		if n, err := strconv.Atoi(line); err == nil {
+			if n >= 1 && n <= min(len(shown), maxShown) {
+				return shown[n-1], nil
+			}
+			fmt.Fprintf(out, "No entry %d.\n", n)
+			continue
+		}
+
+		matches := Filter(options, line)
+		if len(matches) == 0 {
+			fmt.Fprintf(out, "No matches for %q.\n", line)
+			shown = options
+			continue
+		}
+		shown = matches
+	}
+}
+
+// Filter returns the options whose labels fuzzily match query, ignoring case.
+// Labels containing query as a substring come first, followed by labels that
+// contain its characters in order; each group keeps the original order.
+func Filter(options []Option, query string) []Option {
+	query = strings.ToLower(query)
+	type match struct {
+		opt   Option
+		exact bool
+	}
+	var matches []match
+	for _, opt := range options {
+		label := strings.ToLower(opt.Label)
+		switch {
+		case strings.Contains(label, query):
+			matches = append(matches, match{opt, true})
+		case isSubsequence(query, label):
+			matches = append(matches, match{opt, false})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].exact && !matches[j].exact
+	})
+
+	result := make([]Option, len(matches))
+	for i, m := range matches {
+		result[i] = m.opt
+	}
+	return result
+}
+
+// isSubsequence reports whether the runes of sub appear in s in order.
+func isSubsequence(sub, s string) bool {
+	rest := []rune(sub)
+	for _, r := range s {
+		if len(rest) == 0 {
+			break
+		}
+		if r == rest[0] {
+			rest = rest[1:]
+		}
+	}
+	return len(rest) == 0
+}
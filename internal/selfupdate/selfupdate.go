@@ -0,0 +1,350 @@
+// Package selfupdate finds the latest mp release on GitHub and replaces the
+// running binary with it. Release archives are the ones .goreleaser.yml
+// produces: mp_<version>_<os>_<arch>.tar.gz (.zip on Windows), listed with
+// their SHA-256 sums in checksums.txt.
+package selfupdate
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Repo is the GitHub repository releases are published to.
+const Repo = "aviadshiber/mp"
+
+// checksumsAsset is the name of the release asset listing archive checksums.
+const checksumsAsset = "checksums.txt"
+
+// maxBinarySize bounds how much is read from an archive entry, as a guard
+// against a corrupt or hostile archive.
+const maxBinarySize = 512 << 20
+
+var httpClient = &http.Client{Timeout: 5 * time.Minute}
+
+// Release is a published GitHub release.
+type Release struct {
+	Tag    string  `json:"tag_name"`
+	URL    string  `json:"html_url"`
+	Assets []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Version returns the release tag without its leading "v".
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.Tag, "v")
+}
+
+// Asset returns the asset named name.
+func (r *Release) Asset(name string) (Asset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Asset{}, false
+}
+
+// Latest fetches the latest published release of Repo.
+func Latest() (*Release, error) {
+	req, err := http.NewRequest(http.MethodGet, "https://api.github.com/repos/"+Repo+"/releases/latest", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching latest release: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching latest release: GitHub returned %s", resp.Status)
+	}
+
+	var release Release
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("decoding latest release: %w", err)
+	}
+	if release.Tag == "" {
+		return nil, errors.New("latest release has no tag")
+	}
+	return &release, nil
+}
+
+// ArchiveName returns the name of the release archive for version, goos, and
+// goarch.
+func ArchiveName(version, goos, goarch string) string {
+	ext := ".tar.gz"
+	if goos == "windows" {
+		ext = ".zip"
+	}
+	return fmt.Sprintf("mp_%s_%s_%s%s", strings.TrimPrefix(version, "v"), goos, goarch, ext)
+}
+
+// Newer reports whether version latest is newer than current. Versions are
+// compared by their dot-separated numeric parts; a current version that is
+// not of that form, such as "dev", is never considered up to date.
+func Newer(latest, current string) bool {
+	l, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	c, ok := parseVersion(current)
+	if !ok {
+		return true
+	}
+	for i := range max(len(l), len(c)) {
+		var a, b int
+		if i < len(l) {
+			a = l[i]
+		}
+		if i < len(c) {
+			b = c[i]
+		}
+		if a != b {
+			return a > b
+		}
+	}
+	return false
+}
+
+// parseVersion splits "v1.2.3" into its numeric parts, ignoring any
+// pre-release or build suffix.
+func parseVersion(v string) ([]int, bool) {
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	if v == "" {
+		return nil, false
+	}
+	fields := strings.Split(v, ".")
+	parts := make([]int, len(fields))
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
+
+// Download fetches the named archive of release, verifies it against the
+// release's checksums.txt, and returns the mp binary it contains.
+func Download(release *Release, archive string) ([]byte, error) {
+	asset, ok := release.Asset(archive)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no asset %s for this platform", release.Tag, archive)
+	}
+	sums, ok := release.Asset(checksumsAsset)
+	if !ok {
+		return nil, fmt.Errorf("release %s has no %s; refusing to install an unverified binary", release.Tag, checksumsAsset)
+	}
+
+	sumsData, err := fetch(sums.URL)
+	if err != nil {
+		return nil, err
+	}
+	want, err := checksumFor(sumsData, archive)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := fetch(asset.URL)
+	if err != nil {
+		return nil, err
+	}
+	got := sha256.Sum256(data)
+	if hex.EncodeToString(got[:]) != want {
+		return nil, fmt.Errorf("checksum mismatch for %s: expected %s, got %x", archive, want, got)
+	}
+
+	if strings.HasSuffix(archive, ".zip") {
+		return extractZip(data, "mp.exe")
+	}
+	return extractTarGz(data, "mp")
+}
+
+// fetch downloads url into memory.
+func fetch(url string) ([]byte, error) {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", path.Base(url), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading %s: %s", path.Base(url), resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", path.Base(url), err)
+	}
+	return data, nil
+}
+
+// checksumFor finds the SHA-256 of name in a checksums.txt listing, whose
+// lines are "<hex sum>  <file name>".
+func checksumFor(sums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("%s does not list %s", checksumsAsset, name)
+}
+
+func extractTarGz(data []byte, name string) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("reading archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading archive: %w", err)
+		}
+		if hdr.Typeflag == tar.TypeReg && path.Base(hdr.Name) == name {
+			return readLimited(tr)
+		}
+	}
+	return nil, fmt.Errorf("archive does not contain %s", name)
+}
+
+func extractZip(data []byte, name string) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("reading archive: %w", err)
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || path.Base(f.Name) != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("reading archive: %w", err)
+		}
+		defer rc.Close()
+		return readLimited(rc)
+	}
+	return nil, fmt.Errorf("archive does not contain %s", name)
+}
+
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxBinarySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading archive: %w", err)
+	}
+	if len(data) > maxBinarySize {
+		return nil, errors.New("binary in archive is implausibly large")
+	}
+	return data, nil
+}
+
+// Executable returns the path of the running binary with symlinks resolved,
+// which is the file Replace overwrites.
+func Executable() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(exe)
+}
+
+// CheckWritable reports an error if the binary at exe cannot be replaced,
+// which requires creating a file in its directory.
+func CheckWritable(exe string) error {
+	f, err := os.CreateTemp(filepath.Dir(exe), ".mp-update-*")
+	if err != nil {
+		return fmt.Errorf("cannot replace %s: %s is not writable; rerun with sufficient permissions or reinstall mp with the tool you installed it with",
+			exe, filepath.Dir(exe))
+	}
+	name := f.Name()
+	_ = f.Close()
+	return os.Remove(name)
+}
+
+// Replace atomically replaces the binary at exe with data: the new binary is
+// written to a temporary file in the same directory and renamed over exe, so
+// exe is never left partially written.
+func Replace(exe string, data []byte) error {
+	mode := os.FileMode(0o755)
+	if info, err := os.Stat(exe); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(exe)
+	f, err := os.CreateTemp(dir, ".mp-update-*")
+	if err != nil {
+		return fmt.Errorf("cannot write to %s: %w", dir, err)
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp) // no-op once renamed
+
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing new binary: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writing new binary: %w", err)
+	}
+	if err := os.Chmod(tmp, mode); err != nil {
+		return err
+	}
+
+	// Windows cannot rename over a running executable, but it can move it
+	// out of the way first. The old binary stays behind as exe.old until the
+	// next update, since it cannot be deleted while it runs.
+	if runtime.GOOS == "windows" {
+		old := exe + ".old"
+		_ = os.Remove(old)
+		if err := os.Rename(exe, old); err != nil {
+			return fmt.Errorf("replacing %s: %w", exe, err)
+		}
+		if err := os.Rename(tmp, exe); err != nil {
+			_ = os.Rename(old, exe)
+			return fmt.Errorf("replacing %s: %w", exe, err)
+		}
+		return nil
+	}
+	if err := os.Rename(tmp, exe); err != nil {
+		return fmt.Errorf("replacing %s: %w", exe, err)
+	}
+	return nil
+}